@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"gobf"
+	"gobf/engine"
+	"gobf/execution"
+	"gobf/instructions"
+	"gobf/parser"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func main() {
+	memorySize := flag.Uint("memory-size", gobf.DefaultMemorySize, "Number of cells in the memory available to the program")
+	cellSize := flag.Uint("cell-size", 8, "Size (in bits) of a single memory cell: 8, 16 or 32")
+	var dumpGeneratedJitCode jitDumpFlag
+	flag.Var(&dumpGeneratedJitCode, "dump-jit", "Dump a disassembly of the generated JIT code to stderr, -dump-jit=hex dumps the code as hex")
+	dumpIR := flag.Bool("dump-ir", false, "Dump the instructions after parsing and after every optimizer pass to stderr")
+	disableInstructionOptimizer := flag.Bool("disable-instruction-optimizer", false, "Disable optimizer of JIT code")
+	eofBehaviour := flag.String("eof", execution.EOFUnchanged.String(), "Value stored by ',' when no more input is available: zero, minus-one or unchanged")
+	unbuffered := flag.Bool("unbuffered", false, "Write output immediately instead of buffering it, for interactive programs")
+	boundsCheck := flag.Bool("bounds-check", false, "Stop the program with an error when it moves outside of its memory")
+	maxSteps := flag.Uint64("max-steps", 0, "Stop the program with an error after this many loop iterations, 0 for no limit")
+	timeout := flag.Duration("timeout", 0, "Stop the program with an error when it runs longer than this, e.g. 5s, 0 for no limit")
+	passes := flag.String("passes", "", fmt.Sprintf("Comma separated optimizer passes to run instead of those of the optimization level (available: %s)", strings.Join(instructions.NewOptimizer().Passes(), ", ")))
+	disabledPasses := flag.String("disable-passes", "", "Comma separated optimizer passes to skip, e.g. -O3 -disable-passes=constants")
+	level := instructions.MaxLevel
+	flag.Var(levelFlag{&level, 0}, "O0", "Don't optimize the program, and generate simple code")
+	flag.Var(levelFlag{&level, 1}, "O1", "Only merge instructions next to each other, and generate simple code")
+	flag.Var(levelFlag{&level, 2}, "O2", "Also replace common loops and fold pointer moves into instructions")
+	flag.Var(levelFlag{&level, 3}, "O3", "Also evaluate the start of the program at compile time")
+	var stats statsFlag
+	flag.Var(&stats, "stats", "Print statistics about the optimizer, the generated code and timing to stderr after running, -stats=json prints them as JSON")
+	engineName := flag.String("engine", engine.Default(), fmt.Sprintf("Engine used to execute the program, 'list' to describe them (available: %s)", strings.Join(engine.Available(), ", ")))
+	flag.Parse()
+
+	if *engineName == "list" {
+		listEngines()
+		return
+	}
+
+	if len(flag.Args()) != 1 {
+		log.Printf("gobf: try '%s input.b'\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	eof, err := execution.ParseEOF(*eofBehaviour)
+	if err != nil {
+		log.Printf("gobf: %s\n", err)
+		os.Exit(2)
+	}
+
+	options := gobf.Options{
+		Options: execution.Options{
+			MemorySize:  *memorySize,
+			CellSize:    *cellSize,
+			EOF:         eof,
+			Unbuffered:  *unbuffered,
+			BoundsCheck: *boundsCheck,
+			MaxSteps:    *maxSteps,
+		},
+		Engine:           *engineName,
+		DisableOptimizer: *disableInstructionOptimizer,
+	}
+
+	if *passes != "" {
+		options.Passes = strings.Split(*passes, ",")
+	}
+
+	if *disabledPasses != "" {
+		options.DisabledPasses = strings.Split(*disabledPasses, ",")
+	}
+
+	if *dumpIR {
+		options.Inspect = func(stage string, stageInstructions []instructions.Instruction) {
+			if _, err := fmt.Fprintf(os.Stderr, "after %s: %d instructions\n%s\n", stage, len(stageInstructions), instructions.Dump(stageInstructions)); err != nil {
+				log.Printf("error writing instructions to stderr: %s\n", err)
+			}
+		}
+	}
+
+	// A level of 0 selects the default level in gobf.Options, -O0 disables the optimizer unless passes are selected
+	if level == 0 {
+		options.SimpleCodegen = true
+		options.DisableOptimizer = options.DisableOptimizer || options.Passes == nil
+	} else {
+		options.Level = level
+	}
+	if err := options.Validate(); err != nil {
+		log.Printf("gobf: %s\n", err)
+		os.Exit(2)
+	}
+
+	inputData := parseInput(flag.Arg(0))
+
+	compileStart := time.Now()
+
+	program, err := gobf.Compile(inputData, options)
+	if err != nil {
+		var parseError *parser.ParseError
+		if errors.As(err, &parseError) {
+			log.Printf("unrecoverable parser error: %s\n%s", err, sourceSnippet(inputData, parseError))
+		} else {
+			log.Printf("compile error: %s\n", err)
+		}
+
+		os.Exit(1)
+	}
+
+	compileTime := time.Since(compileStart)
+
+	terminalSettings := disableTerminalInputBuffering()
+	defer resetTerminal(terminalSettings)
+
+	if dumpGeneratedJitCode != "" {
+		dump, ok := program.Disassembly()
+		if dumpGeneratedJitCode == "hex" {
+			var code []byte
+			code, ok = program.GeneratedCode()
+			dump = hex.EncodeToString(code)
+		}
+
+		if !ok {
+			log.Printf("engine '%s' does not generate JIT code\n", *engineName)
+		} else if _, err := os.Stderr.WriteString(dump); err != nil {
+			log.Printf("error writing jit code to stderr: %s\n", err)
+		}
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	runStart := time.Now()
+	err = program.Run(ctx, os.Stdin, os.Stdout)
+
+	if stats != "" {
+		programReport := report{
+			Optimizer:     program.OptimizerStats(),
+			CompileTime:   compileTime,
+			ExecutionTime: time.Since(runStart),
+		}
+
+		if code, ok := program.GeneratedCode(); ok {
+			size := len(code)
+			programReport.GeneratedCode = &size
+		}
+
+		if err := programReport.write(os.Stderr, stats); err != nil {
+			log.Printf("error writing stats to stderr: %s\n", err)
+		}
+	}
+
+	if err := program.Close(); err != nil {
+		log.Printf("error closing program: %s\n", err)
+	}
+
+	if err != nil {
+		resetTerminal(terminalSettings)
+
+		log.Printf("runtime error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// levelFlag is one of the -O flags, selecting its optimization level when set
+type levelFlag struct {
+	selected *int
+	level    int
+}
+
+func (flag levelFlag) String() string {
+	return strconv.FormatBool(flag.selected != nil && *flag.selected == flag.level)
+}
+
+func (flag levelFlag) Set(value string) error {
+	set, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+
+	if set {
+		*flag.selected = flag.level
+	}
+
+	return nil
+}
+
+func (flag levelFlag) IsBoolFlag() bool {
+	return true
+}
+
+// jitDumpFlag is the -dump-jit flag, selecting the format of the dump: asm, hex, or none when empty
+type jitDumpFlag string
+
+func (flag *jitDumpFlag) String() string {
+	if flag == nil {
+		return ""
+	}
+
+	return string(*flag)
+}
+
+func (flag *jitDumpFlag) Set(value string) error {
+	switch value {
+	case "true", "asm":
+		*flag = "asm"
+	case "hex":
+		*flag = "hex"
+	case "false":
+		*flag = ""
+	default:
+		return fmt.Errorf("unknown jit dump format '%s', must be asm or hex", value)
+	}
+
+	return nil
+}
+
+func (flag *jitDumpFlag) IsBoolFlag() bool {
+	return true
+}
+
+func listEngines() {
+	for _, name := range engine.Available() {
+		selectedEngine, err := engine.New(name, execution.Options{})
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Printf("%-12s %s\n", name, selectedEngine.Describe())
+	}
+}
+
+// sourceSnippet returns the line of the input containing the error, with a caret pointing at the offending character
+func sourceSnippet(input string, parseError *parser.ParseError) string {
+	lineStart := strings.LastIndexByte(input[:parseError.Offset], '\n') + 1
+
+	lineEnd := strings.IndexByte(input[parseError.Offset:], '\n')
+	if lineEnd == -1 {
+		lineEnd = len(input)
+	} else {
+		lineEnd += parseError.Offset
+	}
+
+	// Keep tabs in the indentation of the caret, so it lines up with the source line
+	indentation := []rune(input[lineStart:parseError.Offset])
+	for i, character := range indentation {
+		if character != '\t' {
+			indentation[i] = ' '
+		}
+	}
+
+	gutter := fmt.Sprintf("%d | ", parseError.Line)
+
+	return fmt.Sprintf("%s%s\n%*s%s^\n", gutter, strings.TrimRight(input[lineStart:lineEnd], "\r"), len(gutter), "| ", string(indentation))
+}
+
+func parseInput(arg string) string {
+	if arg == "-" {
+		stdin, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			panic(err)
+		}
+
+		return string(stdin)
+	}
+
+	contents, err := os.ReadFile(arg)
+	if err != nil {
+		panic(err)
+	}
+
+	return string(contents)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"gobf/instructions"
+	"io"
+	"strings"
+	"time"
+)
+
+// statsFlag is the -stats flag, selecting the format of the report: human, json, or none when empty
+type statsFlag string
+
+func (flag *statsFlag) String() string {
+	if flag == nil {
+		return ""
+	}
+
+	return string(*flag)
+}
+
+func (flag *statsFlag) Set(value string) error {
+	switch value {
+	case "true", "human":
+		*flag = "human"
+	case "json":
+		*flag = "json"
+	case "false":
+		*flag = ""
+	default:
+		return fmt.Errorf("unknown stats format '%s', must be human or json", value)
+	}
+
+	return nil
+}
+
+func (flag *statsFlag) IsBoolFlag() bool {
+	return true
+}
+
+// report is printed by the -stats flag, describing how a program was compiled and run
+type report struct {
+	Optimizer instructions.Stats `json:"optimizer"`
+	// GeneratedCode is the size of the generated machine code in bytes, nil when the engine doesn't generate any
+	GeneratedCode *int          `json:"generated_code_bytes,omitempty"`
+	CompileTime   time.Duration `json:"compile_time_ns"`
+	ExecutionTime time.Duration `json:"execution_time_ns"`
+}
+
+// write writes the report to output in the given format
+func (report report) write(output io.Writer, format statsFlag) error {
+	if format == "json" {
+		encoded, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+
+		_, err = output.Write(append(encoded, '\n'))
+
+		return err
+	}
+
+	var text strings.Builder
+
+	fmt.Fprintf(&text, "instructions:   %d parsed, %d after optimizing\n", report.Optimizer.InstructionsBefore, report.Optimizer.InstructionsAfter)
+	for _, pass := range report.Optimizer.Passes {
+		fmt.Fprintf(&text, "  %-12s  %8d %-23s %8d instructions left  %s\n", pass.Name, pass.Changes, pass.Unit, pass.Instructions, pass.Duration)
+	}
+
+	fmt.Fprintf(&text, "dead code:      %d instructions removed\n", report.Optimizer.DeadInstructions)
+
+	if report.GeneratedCode != nil {
+		fmt.Fprintf(&text, "generated code: %d bytes\n", *report.GeneratedCode)
+	}
+
+	fmt.Fprintf(&text, "compile time:   %s\n", report.CompileTime)
+	fmt.Fprintf(&text, "execution time: %s\n", report.ExecutionTime)
+
+	_, err := io.WriteString(output, text.String())
+
+	return err
+}
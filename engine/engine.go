@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"gobf/execution"
+	"gobf/instructions"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Engine compiles parsed instructions to something it can execute, and executes it. Engines holding on to resources
+// other than memory after compiling, like files, implement io.Closer to release them
+type Engine interface {
+	Compile(parsedInstructions []instructions.Instruction) error
+	// Run executes the compiled program, reading input from input and writing output to output. It stops with an error
+	// matching execution.ErrCanceled once ctx is done, and with execution.ErrStepLimit when the step limit is exceeded
+	Run(ctx context.Context, input io.Reader, output io.Writer) error
+	Describe() string
+}
+
+// CodeGenerator is implemented by engines which generate machine code that can be inspected
+type CodeGenerator interface {
+	GeneratedCode() []byte
+	// Disassemble returns a listing of the generated code, with the instruction every part of it was generated for
+	Disassemble() string
+}
+
+type Factory func(options execution.Options) Engine
+
+// optionsChecker is implemented by engines which don't support every option, so unsupported options are rejected
+// before compiling
+type optionsChecker interface {
+	checkOptions() error
+}
+
+var registry = map[string]Factory{}
+
+// Register makes an engine available by name, engines register themselves for the platforms they support
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+// Available returns the names of all engines which can be used on the current platform
+func Available() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Default returns the fastest engine available, a JIT if one exists for the current platform and the interpreter otherwise
+func Default() string {
+	for _, name := range Available() {
+		if strings.HasPrefix(name, "jit-") {
+			return name
+		}
+	}
+
+	return "interp"
+}
+
+func New(name string, options execution.Options) (Engine, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, errors.New("unknown engine '" + name + "', available engines: " + strings.Join(Available(), ", "))
+	}
+
+	engine := factory(options)
+
+	if checker, ok := engine.(optionsChecker); ok {
+		if err := checker.checkOptions(); err != nil {
+			return nil, err
+		}
+	}
+
+	return engine, nil
+}
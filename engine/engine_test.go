@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"gobf/execution"
+	"gobf/parser"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestEngine_Available(t *testing.T) {
+	available := Available()
+
+	assert.Contains(t, available, "interp")
+	assert.Contains(t, available, Default())
+	assert.IsIncreasing(t, available)
+}
+
+func TestEngine_New(t *testing.T) {
+	for _, name := range Available() {
+		t.Run(name, func(t *testing.T) {
+			engine, err := New(name, execution.Options{MemorySize: 100})
+
+			assert.NoError(t, err)
+			assert.NotEmpty(t, engine.Describe())
+		})
+	}
+}
+
+func TestEngine_NewUnknown(t *testing.T) {
+	_, err := New("unknown", execution.Options{MemorySize: 100})
+
+	assert.ErrorContains(t, err, "unknown engine 'unknown'")
+}
+
+func TestEngine_TranspilerRunTwice(t *testing.T) {
+	if !contains(Available(), "transpile-c") {
+		t.Skip("no C compiler available")
+	}
+
+	instructionParser := parser.NewParser()
+	parsedInstructions, err := instructionParser.Parse("++++++++[>++++++++<-]>+.")
+	assert.NoError(t, err)
+
+	engine := &transpilerEngine{options: execution.Options{MemorySize: 100}}
+	assert.NoError(t, engine.Compile(parsedInstructions))
+	directory := engine.directory
+
+	for i := 0; i < 2; i++ {
+		output := &bytes.Buffer{}
+		assert.NoError(t, engine.Run(context.Background(), strings.NewReader(""), output))
+		assert.Equal(t, "A", output.String())
+	}
+
+	assert.NoError(t, engine.Close())
+	assert.NoDirExists(t, directory)
+	assert.ErrorContains(t, engine.Run(context.Background(), strings.NewReader(""), &bytes.Buffer{}), "no compiled program to run")
+}
+
+func TestEngine_TranspilerCompileError(t *testing.T) {
+	if !contains(Available(), "transpile-c") {
+		t.Skip("no C compiler available")
+	}
+
+	temporary := t.TempDir()
+	t.Setenv("TMPDIR", temporary)
+	// cc can't be found anymore
+	t.Setenv("PATH", "")
+
+	engine := &transpilerEngine{options: execution.Options{MemorySize: 100}}
+	assert.ErrorContains(t, engine.Compile(nil), "failed to compile transpiled program")
+
+	entries, err := os.ReadDir(temporary)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestEngine_TranspilerBoundsCheck(t *testing.T) {
+	if !contains(Available(), "transpile-c") {
+		t.Skip("no C compiler available")
+	}
+
+	options := execution.Options{MemorySize: 100, BoundsCheck: true}
+
+	_, err := New("transpile-c", options)
+	assert.EqualError(t, err, "bounds checking is not supported by transpile-c, use another engine")
+
+	engine := &transpilerEngine{options: options}
+	assert.EqualError(t, engine.Compile(nil), "bounds checking is not supported by transpile-c, use another engine")
+}
+
+func contains(names []string, name string) bool {
+	for _, candidate := range names {
+		if candidate == name {
+			return true
+		}
+	}
+
+	return false
+}
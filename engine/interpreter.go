@@ -0,0 +1,20 @@
+package engine
+
+import (
+	"gobf/execution"
+	"gobf/interpreter"
+)
+
+type interpreterEngine struct {
+	*interpreter.Interpreter
+}
+
+func init() {
+	Register("interp", func(options execution.Options) Engine {
+		return &interpreterEngine{interpreter.NewInterpreter(options)}
+	})
+}
+
+func (engine *interpreterEngine) Describe() string {
+	return "Portable interpreter, works on every platform"
+}
@@ -0,0 +1,24 @@
+package engine
+
+import (
+	"gobf/execution"
+	"gobf/jit"
+)
+
+type jitEngine struct {
+	*jit.Jit
+	description string
+}
+
+func newJitEngine(description string) Factory {
+	return func(options execution.Options) Engine {
+		return &jitEngine{
+			jit.NewJit(options),
+			description,
+		}
+	}
+}
+
+func (engine *jitEngine) Describe() string {
+	return engine.description
+}
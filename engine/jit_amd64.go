@@ -0,0 +1,7 @@
+//go:build linux && amd64
+
+package engine
+
+func init() {
+	Register("jit-amd64", newJitEngine("JIT compiler generating x86_64 machine code"))
+}
@@ -0,0 +1,7 @@
+//go:build (darwin || linux) && arm64
+
+package engine
+
+func init() {
+	Register("jit-arm64", newJitEngine("JIT compiler generating AArch64 machine code"))
+}
@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"gobf/execution"
+	"gobf/instructions"
+	"gobf/transpiler"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// transpilerEngine converts the program to C, compiles it with the system C compiler and runs the resulting binary.
+// The binary is kept in directory until Close is called, so the program can be run any number of times
+type transpilerEngine struct {
+	options   execution.Options
+	directory string
+}
+
+func init() {
+	// Only available when a C compiler can be found
+	if _, err := exec.LookPath("cc"); err != nil {
+		return
+	}
+
+	Register("transpile-c", func(options execution.Options) Engine {
+		return &transpilerEngine{options: options}
+	})
+}
+
+func (engine *transpilerEngine) Compile(parsedInstructions []instructions.Instruction) error {
+	if err := engine.checkOptions(); err != nil {
+		return err
+	}
+
+	// a program compiled before is replaced
+	if err := engine.Close(); err != nil {
+		return err
+	}
+
+	directory, err := os.MkdirTemp("", "gobf")
+	if err != nil {
+		return errors.New("failed to create build directory: " + err.Error())
+	}
+
+	if err := build(directory, parsedInstructions, engine.options); err != nil {
+		os.RemoveAll(directory)
+		return err
+	}
+
+	engine.directory = directory
+
+	// Removes the binary when the engine is garbage collected without being closed
+	runtime.SetFinalizer(engine, (*transpilerEngine).Close)
+
+	return nil
+}
+
+// checkOptions makes sure the options are supported, the generated C doesn't check bounds
+func (engine *transpilerEngine) checkOptions() error {
+	if err := engine.options.Validate(); err != nil {
+		return err
+	}
+
+	if engine.options.BoundsCheck {
+		return errors.New("bounds checking is not supported by transpile-c, use another engine")
+	}
+
+	return nil
+}
+
+// build transpiles the instructions to C in directory, and compiles them to a binary named program next to it
+func build(directory string, parsedInstructions []instructions.Instruction, options execution.Options) error {
+	source := filepath.Join(directory, "program.c")
+	if err := os.WriteFile(source, []byte(transpiler.TranspileToC(parsedInstructions, options)), 0o600); err != nil {
+		return errors.New("failed to write transpiled program: " + err.Error())
+	}
+
+	optimization := "-O2"
+	if options.SimpleCodegen {
+		optimization = "-O0"
+	}
+
+	compiler := exec.Command("cc", optimization, "-o", filepath.Join(directory, "program"), source)
+	compiler.Stderr = os.Stderr
+
+	if err := compiler.Run(); err != nil {
+		return errors.New("failed to compile transpiled program: " + err.Error())
+	}
+
+	return nil
+}
+
+func (engine *transpilerEngine) Run(ctx context.Context, input io.Reader, output io.Writer) error {
+	if engine.directory == "" {
+		return errors.New("no compiled program to run")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return execution.Canceled(err)
+	}
+
+	// The program is killed once ctx is done
+	program := exec.CommandContext(ctx, filepath.Join(engine.directory, "program"))
+	program.Stdin = input
+	program.Stdout = output
+	program.Stderr = os.Stderr
+
+	if err := program.Run(); err != nil {
+		if ctx.Err() != nil {
+			return execution.Canceled(ctx.Err())
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == transpiler.StepLimitExitCode {
+			return execution.ErrStepLimit
+		}
+
+		return errors.New("failed to run transpiled program: " + err.Error())
+	}
+
+	return nil
+}
+
+// Close removes the compiled binary, the program can't be run anymore afterwards
+func (engine *transpilerEngine) Close() error {
+	if engine.directory == "" {
+		return nil
+	}
+
+	runtime.SetFinalizer(engine, nil)
+
+	if err := os.RemoveAll(engine.directory); err != nil {
+		return errors.New("failed to remove build directory: " + err.Error())
+	}
+
+	engine.directory = ""
+
+	return nil
+}
+
+func (engine *transpilerEngine) Describe() string {
+	return "Transpiles the program to C and runs the binary built by the system C compiler"
+}
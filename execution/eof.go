@@ -0,0 +1,50 @@
+package execution
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EOF decides what the Read instruction stores in the current cell when no more input is available
+type EOF int
+
+const (
+	// EOFUnchanged leaves the current cell unchanged
+	EOFUnchanged EOF = iota
+	// EOFZero stores 0 in the current cell
+	EOFZero
+	// EOFMinusOne stores -1 in the current cell, which is the largest value a cell can hold
+	EOFMinusOne
+)
+
+var eofNames = map[EOF]string{
+	EOFUnchanged: "unchanged",
+	EOFZero:      "zero",
+	EOFMinusOne:  "minus-one",
+}
+
+// ParseEOF returns the EOF behaviour with the given name, as used on the command line
+func ParseEOF(name string) (EOF, error) {
+	for eof, eofName := range eofNames {
+		if eofName == name {
+			return eof, nil
+		}
+	}
+
+	names := make([]string, 0, len(eofNames))
+	for _, eofName := range eofNames {
+		names = append(names, eofName)
+	}
+	sort.Strings(names)
+
+	return EOFUnchanged, fmt.Errorf("unknown eof behaviour '%s', must be one of: %s", name, strings.Join(names, ", "))
+}
+
+func (eof EOF) String() string {
+	if name, ok := eofNames[eof]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("EOF(%d)", int(eof))
+}
@@ -0,0 +1,71 @@
+package execution
+
+import (
+	"errors"
+	"fmt"
+	"gobf/instructions"
+)
+
+// ErrStepLimit is returned when a program executes more steps than allowed by Options.MaxSteps
+var ErrStepLimit = errors.New("step limit exceeded")
+
+// ErrCanceled is returned when the context of a running program is canceled or its deadline is exceeded, the error
+// returned by Canceled also matches the error of the context
+var ErrCanceled = errors.New("program canceled")
+
+// CancelCheckInterval is the number of steps executed between checks of the context of a running program
+const CancelCheckInterval = 1 << 20
+
+// Canceled returns an error matching both ErrCanceled and cause, which is the error of the canceled context
+func Canceled(cause error) error {
+	return &canceledError{cause}
+}
+
+type canceledError struct {
+	cause error
+}
+
+func (err *canceledError) Error() string {
+	return ErrCanceled.Error() + ": " + err.cause.Error()
+}
+
+func (err *canceledError) Is(target error) bool {
+	return target == ErrCanceled
+}
+
+func (err *canceledError) Unwrap() error {
+	return err.cause
+}
+
+// BoundsError is returned when a program moves its pointer outside of memory while bounds checking is enabled
+type BoundsError struct {
+	// Instruction is the index of the instruction which moved the pointer
+	Instruction int
+	Pointer     int
+	Span        instructions.Span
+}
+
+func (err *BoundsError) Error() string {
+	message := fmt.Sprintf("pointer out of bounds at instruction %d: %d", err.Instruction, err.Pointer)
+	if !err.Span.IsZero() {
+		message += " (source " + err.Span.String() + ")"
+	}
+
+	return message
+}
+
+// TapeError is returned when a program accesses memory outside of its memory without bounds checking, the JIT detects
+// this using the guard pages surrounding memory and the interpreter by checking every access
+type TapeError struct {
+	Overflow bool
+	// Pointer is the offset of the access relative to the start of memory
+	Pointer int
+}
+
+func (err *TapeError) Error() string {
+	if err.Overflow {
+		return fmt.Sprintf("tape overflow: pointer %d is past the end of memory", err.Pointer)
+	}
+
+	return fmt.Sprintf("tape underflow: pointer %d is before the start of memory", err.Pointer)
+}
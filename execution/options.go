@@ -0,0 +1,65 @@
+package execution
+
+import "fmt"
+
+// Options configures how a program is executed
+type Options struct {
+	// MemorySize is the number of cells available to the program
+	MemorySize uint
+	// CellSize is the width of a cell in bits, either 8, 16 or 32. Defaults to 8 bits when not set
+	CellSize uint
+	// EOF decides what Read stores in the current cell when no more input is available, defaults to leaving it unchanged
+	EOF EOF
+	// Unbuffered writes output as soon as the program writes it instead of collecting it in a buffer first, which is
+	// useful for interactive programs. The interpreter always writes output immediately
+	Unbuffered bool
+	// BoundsCheck makes the program fail with a BoundsError when the pointer moves outside of memory,
+	// supported by the JIT and interpreter engines. Only the moves left after optimizing are checked, moves which
+	// cancel each other out like <> are merged before they can fail
+	BoundsCheck bool
+	// MaxSteps makes the program fail with ErrStepLimit once it has executed more than this many steps, 0 doesn't limit
+	// the number of steps. A step is a single execution of JumpUnlessZero, so every loop iteration is a step, this bounds
+	// the running time of any program since code without loops always ends
+	MaxSteps uint64
+	// SimpleCodegen makes engines generate straightforward code, which is quicker to generate and easier to inspect but
+	// slower: the amd64 JIT scans memory without vector instructions, and transpile-c compiles without optimizations
+	SimpleCodegen bool
+}
+
+// Validate checks whether the options are supported by every engine
+func (options Options) Validate() error {
+	switch options.CellSize {
+	case 0, 8, 16, 32:
+	default:
+		return fmt.Errorf("unsupported cell size %d, must be 8, 16 or 32", options.CellSize)
+	}
+
+	if _, ok := eofNames[options.EOF]; !ok {
+		return fmt.Errorf("unsupported eof behaviour %s", options.EOF)
+	}
+
+	return nil
+}
+
+// CellBytes returns the number of bytes used by a single cell
+func (options Options) CellBytes() int {
+	if options.CellSize == 0 {
+		return 1
+	}
+
+	return int(options.CellSize / 8)
+}
+
+// CellMask returns the largest value a cell can hold, values wrap around to 0 after it
+func (options Options) CellMask() uint32 {
+	return uint32(1<<(options.CellBytes()*8) - 1)
+}
+
+// EOFValue returns the value Read stores in the current cell on EOF, only used when EOF isn't EOFUnchanged
+func (options Options) EOFValue() uint32 {
+	if options.EOF == EOFMinusOne {
+		return options.CellMask()
+	}
+
+	return 0
+}
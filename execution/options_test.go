@@ -0,0 +1,66 @@
+package execution
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOptions_Cells(t *testing.T) {
+	var tests = []struct {
+		cellSize uint
+		bytes    int
+		mask     uint32
+	}{
+		{0, 1, 0xFF},
+		{8, 1, 0xFF},
+		{16, 2, 0xFFFF},
+		{32, 4, 0xFFFFFFFF},
+	}
+
+	for _, test := range tests {
+		options := Options{CellSize: test.cellSize}
+
+		assert.NoError(t, options.Validate())
+		assert.Equal(t, test.bytes, options.CellBytes())
+		assert.Equal(t, test.mask, options.CellMask())
+	}
+}
+
+func TestOptions_ValidateCellSize(t *testing.T) {
+	options := Options{CellSize: 12}
+
+	assert.EqualError(t, options.Validate(), "unsupported cell size 12, must be 8, 16 or 32")
+}
+
+func TestOptions_EOF(t *testing.T) {
+	var tests = []struct {
+		name     string
+		eof      EOF
+		cellSize uint
+		value    uint32
+	}{
+		{"unchanged", EOFUnchanged, 8, 0},
+		{"zero", EOFZero, 16, 0},
+		{"minus-one", EOFMinusOne, 8, 0xFF},
+		{"minus-one", EOFMinusOne, 32, 0xFFFFFFFF},
+	}
+
+	for _, test := range tests {
+		eof, err := ParseEOF(test.name)
+		assert.NoError(t, err)
+		assert.Equal(t, test.eof, eof)
+		assert.Equal(t, test.name, eof.String())
+
+		options := Options{CellSize: test.cellSize, EOF: eof}
+
+		assert.NoError(t, options.Validate())
+		assert.Equal(t, test.value, options.EOFValue())
+	}
+}
+
+func TestOptions_ParseEOFUnknown(t *testing.T) {
+	_, err := ParseEOF("nothing")
+
+	assert.EqualError(t, err, "unknown eof behaviour 'nothing', must be one of: minus-one, unchanged, zero")
+	assert.EqualError(t, Options{EOF: 5}.Validate(), "unsupported eof behaviour EOF(5)")
+}
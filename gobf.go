@@ -0,0 +1,200 @@
+// Package gobf compiles and runs brainfuck programs, combining the parser, the instruction optimizer and the engines
+// into a single API which can be embedded in other Go programs
+package gobf
+
+import (
+	"context"
+	"gobf/engine"
+	"gobf/execution"
+	"gobf/instructions"
+	"gobf/parser"
+	"io"
+)
+
+// ErrStepLimit is returned by Run when a program executes more steps than allowed by Options.MaxSteps
+var ErrStepLimit = execution.ErrStepLimit
+
+// ErrCanceled is returned by Run when its context is canceled or its deadline is exceeded, the error also matches the
+// error of the context
+var ErrCanceled = execution.ErrCanceled
+
+// DefaultMemorySize is the number of cells available to a program when no memory size is configured
+const DefaultMemorySize = 30_000
+
+// Options configures how a program is compiled and executed
+type Options struct {
+	execution.Options
+	// Engine is the name of the engine executing the program, defaults to the fastest one available
+	Engine string
+	// DisableOptimizer executes the parsed instructions without optimizing them first
+	DisableOptimizer bool
+	// Level is the optimization level, from 1 to instructions.MaxLevel. Higher levels run more optimizer passes, and
+	// level 1 generates simple code, see execution.Options.SimpleCodegen. Defaults to instructions.MaxLevel when not set
+	Level int
+	// Passes are the names of the optimizer passes to run instead of the passes of the level
+	Passes []string
+	// DisabledPasses are the names of optimizer passes which are skipped, see instructions.Optimizer
+	DisabledPasses []string
+	// Inspect is called with the instructions after parsing, with "parse" as stage, and after every optimizer pass,
+	// with the name of the pass as stage. It must not modify the instructions
+	Inspect func(stage string, instructions []instructions.Instruction)
+}
+
+// Program is a compiled brainfuck program, ready to be executed
+type Program struct {
+	engine         engine.Engine
+	instructions   []instructions.Instruction
+	optimizerStats instructions.Stats
+}
+
+// Validate checks whether the options are supported, and the engine is available on the current platform
+func (options Options) Validate() error {
+	if err := options.Options.Validate(); err != nil {
+		return err
+	}
+
+	options = options.withDefaults()
+
+	if _, err := options.optimizer(); err != nil {
+		return err
+	}
+
+	_, err := engine.New(options.Engine, options.Options)
+
+	return err
+}
+
+// optimizer returns the optimizer configured by the options
+func (options Options) optimizer() (*instructions.Optimizer, error) {
+	optimizer := instructions.NewOptimizer()
+	optimizer.SetMemorySize(int(options.MemorySize))
+
+	if err := optimizer.SetLevel(options.Level); err != nil {
+		return nil, err
+	}
+
+	if options.Passes != nil {
+		if err := optimizer.SetPasses(options.Passes); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, pass := range options.DisabledPasses {
+		if err := optimizer.Disable(pass); err != nil {
+			return nil, err
+		}
+	}
+
+	optimizer.OnPass(options.Inspect)
+
+	return optimizer, nil
+}
+
+func (options Options) withDefaults() Options {
+	if options.MemorySize == 0 {
+		options.MemorySize = DefaultMemorySize
+	}
+
+	if options.Engine == "" {
+		options.Engine = engine.Default()
+	}
+
+	if options.Level == 0 {
+		options.Level = instructions.MaxLevel
+	}
+
+	if options.Level == 1 {
+		options.SimpleCodegen = true
+	}
+
+	return options
+}
+
+// Compile parses and optimizes the source, and compiles it with the configured engine. Syntax errors are returned as
+// a *parser.ParseError
+func Compile(source string, options Options) (*Program, error) {
+	options = options.withDefaults()
+
+	selectedEngine, err := engine.New(options.Engine, options.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	optimizer, err := options.optimizer()
+	if err != nil {
+		return nil, err
+	}
+
+	instructionParser := parser.NewParser()
+	parsedInstructions, err := instructionParser.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Inspect != nil {
+		options.Inspect("parse", parsedInstructions)
+	}
+
+	optimizerStats := instructions.Stats{InstructionsBefore: len(parsedInstructions), InstructionsAfter: len(parsedInstructions)}
+	if !options.DisableOptimizer {
+		parsedInstructions = optimizer.Optimize(parsedInstructions)
+		optimizerStats = optimizer.Stats()
+	}
+
+	if err := selectedEngine.Compile(parsedInstructions); err != nil {
+		return nil, err
+	}
+
+	return &Program{
+		engine:         selectedEngine,
+		instructions:   parsedInstructions,
+		optimizerStats: optimizerStats,
+	}, nil
+}
+
+// Run executes the program, reading input from input and writing output to output. The program is stopped with an
+// error matching ErrCanceled once ctx is done, and with ErrStepLimit when it exceeds Options.MaxSteps
+func (program *Program) Run(ctx context.Context, input io.Reader, output io.Writer) error {
+	return program.engine.Run(ctx, input, output)
+}
+
+// Close releases the resources held by the engine, like the binary built by transpile-c. The program can't be run
+// anymore afterwards
+func (program *Program) Close() error {
+	if closer, ok := program.engine.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// Instructions returns the instructions executed by the program, after optimization
+func (program *Program) Instructions() []instructions.Instruction {
+	return program.instructions
+}
+
+// OptimizerStats describes what the optimizer changed, like the number of instructions it removed as dead code
+func (program *Program) OptimizerStats() instructions.Stats {
+	return program.optimizerStats
+}
+
+// GeneratedCode returns the machine code generated for the program, when the engine generates any
+func (program *Program) GeneratedCode() ([]byte, bool) {
+	generator, ok := program.engine.(engine.CodeGenerator)
+	if !ok {
+		return nil, false
+	}
+
+	return generator.GeneratedCode(), true
+}
+
+// Disassembly returns a listing of the machine code generated for the program with the instruction it was generated
+// for, when the engine generates any
+func (program *Program) Disassembly() (string, bool) {
+	generator, ok := program.engine.(engine.CodeGenerator)
+	if !ok {
+		return "", false
+	}
+
+	return generator.Disassemble(), true
+}
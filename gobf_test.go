@@ -0,0 +1,249 @@
+package gobf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"gobf/engine"
+	"gobf/execution"
+	"gobf/instructions"
+	"gobf/parser"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGobf_Run(t *testing.T) {
+	for _, name := range engine.Available() {
+		t.Run(name, func(t *testing.T) {
+			program, err := Compile(",[.,]++++++++[>++++++++<-]>+.", Options{Options: execution.Options{EOF: execution.EOFZero}, Engine: name})
+			assert.NoError(t, err)
+			defer program.Close()
+
+			output := &bytes.Buffer{}
+			assert.NoError(t, program.Run(context.Background(), strings.NewReader("echo"), output))
+
+			assert.Equal(t, "echoA", output.String())
+		})
+	}
+}
+
+func TestGobf_RunScanLoops(t *testing.T) {
+	// cells returns a program storing its index plus one in every cell marked with 1 in pattern, and moving the pointer
+	// to start. The zero cell found by a scan is identified by writing the cell next to it
+	cells := func(pattern string, start int) string {
+		var program strings.Builder
+		for index, cell := range pattern {
+			if cell == '1' {
+				program.WriteString(strings.Repeat("+", index+1))
+			}
+
+			program.WriteString(">")
+		}
+
+		return program.String() + strings.Repeat("<", len(pattern)-start)
+	}
+
+	var tests = []struct {
+		name   string
+		input  string
+		output byte
+	}{
+		{"right", cells("1111101", 0) + "[>]<.", 5},
+		{"right skips zero before pointer", cells("10111111110", 2) + "[>]<.", 10},
+		{"right across blocks", cells(strings.Repeat("1", 40)+"0", 3) + "[>]<.", 40},
+		{"right stride", cells("11111111101", 0) + "[>>>]<.", 9},
+		{"left", cells("1011111", 6) + "[<]>.", 3},
+		{"left skips zero after pointer", cells("01111101", 4) + "[<]>.", 2},
+		{"left across blocks", cells("0"+strings.Repeat("1", 40), 38) + "[<]>.", 2},
+		{"zero cell", cells("110", 2) + "[>]<.", 2},
+	}
+
+	for _, name := range engine.Available() {
+		for _, cellSize := range []uint{8, 16} {
+			for _, test := range tests {
+				t.Run(fmt.Sprintf("%s %d %s", name, cellSize, test.name), func(t *testing.T) {
+					program, err := Compile(test.input, Options{Options: execution.Options{CellSize: cellSize}, Engine: name})
+					assert.NoError(t, err)
+					defer program.Close()
+
+					output := &bytes.Buffer{}
+					assert.NoError(t, program.Run(context.Background(), strings.NewReader(""), output))
+
+					assert.Equal(t, []byte{test.output}, output.Bytes())
+				})
+			}
+		}
+	}
+}
+
+func TestGobf_CompileParseError(t *testing.T) {
+	_, err := Compile("+[", Options{})
+
+	var parseError *parser.ParseError
+	assert.ErrorAs(t, err, &parseError)
+	assert.Equal(t, 2, parseError.Column)
+}
+
+func TestGobf_CompileOptimizer(t *testing.T) {
+	program, err := Compile(",+++[-]", Options{})
+	assert.NoError(t, err)
+	assert.Len(t, program.Instructions(), 3)
+	assert.Equal(t, 7, program.OptimizerStats().InstructionsBefore)
+	assert.Equal(t, 3, program.OptimizerStats().InstructionsAfter)
+	assert.Len(t, program.OptimizerStats().Passes, 8)
+
+	program, err = Compile(",+++[-]", Options{DisableOptimizer: true})
+	assert.NoError(t, err)
+	assert.Len(t, program.Instructions(), 7)
+	assert.Equal(t, 7, program.OptimizerStats().InstructionsAfter)
+	assert.Empty(t, program.OptimizerStats().Passes)
+
+	program, err = Compile(",+++[-]", Options{DisabledPasses: []string{"clear", "multiply"}})
+	assert.NoError(t, err)
+	assert.Len(t, program.Instructions(), 5)
+
+	_, err = Compile(",+++[-]", Options{DisabledPasses: []string{"unknown"}})
+	assert.ErrorContains(t, err, "unknown optimizer pass 'unknown'")
+
+	program, err = Compile(",+++[-]", Options{Level: 1})
+	assert.NoError(t, err)
+	assert.Len(t, program.Instructions(), 3)
+
+	program, err = Compile(",+++[-]>", Options{Passes: []string{"consecutive"}})
+	assert.NoError(t, err)
+	assert.Len(t, program.Instructions(), 6)
+}
+
+func TestGobf_CompileDeadLoops(t *testing.T) {
+	program, err := Compile("[a comment, with punctuation.] ,+[-][never runs] .", Options{})
+	assert.NoError(t, err)
+	assert.Len(t, program.Instructions(), 4)
+	assert.Equal(t, 6, program.OptimizerStats().DeadInstructions)
+
+	program, err = Compile("[a comment]", Options{DisableOptimizer: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, program.OptimizerStats().DeadInstructions)
+}
+
+func TestGobf_CompileInspect(t *testing.T) {
+	var stages []string
+	var sizes []int
+
+	inspect := func(stage string, instructions []instructions.Instruction) {
+		stages = append(stages, stage)
+		sizes = append(sizes, len(instructions))
+	}
+
+	_, err := Compile(",+++[-]", Options{Level: 1, Inspect: inspect})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"parse", "clear", "consecutive", "opposing"}, stages)
+	assert.Equal(t, []int{7, 5, 3, 3}, sizes)
+
+	stages, sizes = nil, nil
+
+	_, err = Compile(",+++[-]", Options{DisableOptimizer: true, Inspect: inspect})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"parse"}, stages)
+}
+
+func TestGobf_Disassembly(t *testing.T) {
+	for _, name := range engine.Available() {
+		t.Run(name, func(t *testing.T) {
+			program, err := Compile("+[-].", Options{Engine: name, DisableOptimizer: true})
+			assert.NoError(t, err)
+			defer program.Close()
+
+			disassembly, ok := program.Disassembly()
+			code, generatesCode := program.GeneratedCode()
+			assert.Equal(t, generatesCode, ok)
+
+			if !ok {
+				return
+			}
+
+			assert.NotEmpty(t, code)
+			assert.Contains(t, disassembly, "prologue:\n")
+			assert.Contains(t, disassembly, "\n0 Increment 1 (1:1):\n")
+			assert.Contains(t, disassembly, "\n4 Write (1:5):\n")
+			assert.Contains(t, disassembly, "\nepilogue:\n")
+		})
+	}
+}
+
+func TestGobf_Validate(t *testing.T) {
+	assert.NoError(t, Options{}.Validate())
+	assert.ErrorContains(t, Options{Engine: "unknown"}.Validate(), "unknown engine 'unknown'")
+	assert.ErrorContains(t, Options{DisabledPasses: []string{"unknown"}}.Validate(), "unknown optimizer pass 'unknown'")
+	assert.EqualError(t, Options{Level: 4}.Validate(), "unsupported optimization level 4, must be 0 to 3")
+	assert.EqualError(t, Options{Options: execution.Options{CellSize: 7}}.Validate(), "unsupported cell size 7, must be 8, 16 or 32")
+
+	for _, name := range engine.Available() {
+		if name == "transpile-c" {
+			assert.ErrorContains(t, Options{Options: execution.Options{BoundsCheck: true}, Engine: name}.Validate(), "bounds checking is not supported")
+		}
+	}
+}
+
+func TestGobf_RunBoundsCheckOptimized(t *testing.T) {
+	for _, name := range engine.Available() {
+		if name == "transpile-c" {
+			continue
+		}
+
+		t.Run(name, func(t *testing.T) {
+			options := Options{Options: execution.Options{MemorySize: 8, BoundsCheck: true}, Engine: name}
+
+			// without optimizing, the pointer moving left of the first cell fails
+			options.DisableOptimizer = true
+			program, err := Compile("<>+.", options)
+			assert.NoError(t, err)
+
+			var boundsError *execution.BoundsError
+			assert.ErrorAs(t, program.Run(context.Background(), strings.NewReader(""), &bytes.Buffer{}), &boundsError)
+
+			// the optimizer merges the moves before they can fail
+			options.DisableOptimizer = false
+			options.Level = 1
+			program, err = Compile("<>+.", options)
+			assert.NoError(t, err)
+
+			output := &bytes.Buffer{}
+			assert.NoError(t, program.Run(context.Background(), strings.NewReader(""), output))
+			assert.Equal(t, "\x01", output.String())
+		})
+	}
+}
+
+func TestGobf_RunCanceled(t *testing.T) {
+	program, err := Compile("+.", Options{})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = program.Run(ctx, strings.NewReader(""), &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrCanceled)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGobf_RunLimits(t *testing.T) {
+	for _, name := range engine.Available() {
+		t.Run(name, func(t *testing.T) {
+			program, err := Compile("+[]", Options{Options: execution.Options{MaxSteps: 1000}, Engine: name})
+			assert.NoError(t, err)
+			defer program.Close()
+			assert.ErrorIs(t, program.Run(context.Background(), strings.NewReader(""), &bytes.Buffer{}), ErrStepLimit)
+
+			program, err = Compile("+[]", Options{Engine: name})
+			assert.NoError(t, err)
+			defer program.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			assert.ErrorIs(t, program.Run(ctx, strings.NewReader(""), &bytes.Buffer{}), ErrCanceled)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package instructions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dump returns a human readable listing of instructions, one per line with its index, indented by loop depth, and
+// the source code it was created from. Jump instructions show the index of their matching jump instruction
+func Dump(instructions []Instruction) string {
+	lines := make([]string, len(instructions))
+	indexWidth := len(strconv.Itoa(len(instructions) - 1))
+	width := 0
+	depth := 0
+
+	for index, instruction := range instructions {
+		if instruction.Name == JumpUnlessZero && depth > 0 {
+			depth--
+		}
+
+		lines[index] = fmt.Sprintf("%*d  %s%s", indexWidth, index, strings.Repeat("  ", depth), instruction.Describe())
+		if len(lines[index]) > width {
+			width = len(lines[index])
+		}
+
+		if instruction.Name == JumpIfZero {
+			depth++
+		}
+	}
+
+	var dump strings.Builder
+
+	for index, line := range lines {
+		if instructions[index].Span.IsZero() {
+			dump.WriteString(line + "\n")
+			continue
+		}
+
+		dump.WriteString(fmt.Sprintf("%-*s  %s\n", width, line, instructions[index].Span))
+	}
+
+	return dump.String()
+}
+
+// Describe returns the type of the instruction followed by its operands, like Increment 2 [+1]
+func (instruction Instruction) Describe() string {
+	description := instruction.Name.ToString()
+
+	switch instruction.Name {
+	case JumpIfZero, JumpUnlessZero:
+		return fmt.Sprintf("%s -> %d", description, instruction.Value)
+	case WriteString:
+		return description + " " + strconv.Quote(instruction.Text)
+	case MoveRight, MoveLeft, Increment, Decrement, MulAdd, Set, ScanRight, ScanLeft:
+		description += " " + strconv.Itoa(instruction.Value)
+	}
+
+	if instruction.Offset != 0 {
+		description += fmt.Sprintf(" [%+d]", instruction.Offset)
+	}
+
+	return description
+}
@@ -0,0 +1,41 @@
+package instructions
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInstructions_Dump(t *testing.T) {
+	column := func(offset int) Span {
+		return NewSpan(Position{Line: 1, Column: offset + 1, Offset: offset})
+	}
+
+	assert.Equal(t, ` 0  Read                   1:1
+ 1  JumpIfZero -> 8        1:2
+ 2    Increment 3 [+2]
+ 3    JumpIfZero -> 5      1:4
+ 4      ScanLeft 2
+ 5    JumpUnlessZero -> 3
+ 6    MulAdd -1 [-1]
+ 7    Clear
+ 8  JumpUnlessZero -> 1
+ 9  Set 65 [+1]
+10  WriteString "a\n"
+11  Write [+1]
+`, Dump([]Instruction{
+		{Name: Read, Value: 1, Span: column(0)},
+		{Name: JumpIfZero, Value: 8, Span: column(1)},
+		{Name: Increment, Value: 3, Offset: 2},
+		{Name: JumpIfZero, Value: 5, Span: column(3)},
+		{Name: ScanLeft, Value: 2},
+		{Name: JumpUnlessZero, Value: 3},
+		{Name: MulAdd, Value: -1, Offset: -1},
+		{Name: Clear, Value: 7},
+		{Name: JumpUnlessZero, Value: 1},
+		{Name: Set, Value: 65, Offset: 1},
+		{Name: WriteString, Text: "a\n"},
+		{Name: Write, Value: 1, Offset: 1},
+	}))
+
+	assert.Equal(t, "", Dump(nil))
+}
@@ -17,11 +17,27 @@ const (
 
 	// Clear is an instruction for optimizing zero-ing out a register: [-]
 	Clear
+	// MulAdd adds the current cell multiplied by Value to the cell at Offset from it, used for multiply loops: [->++<]
+	MulAdd
+	// ScanRight and ScanLeft move the pointer by Value cells until it reaches a zero cell, used for scan loops: [>]
+	ScanRight
+	ScanLeft
+	// Set stores Value in the cell at Offset, and WriteString writes Text, used for the start of the program which the
+	// optimizer evaluated at compile time
+	Set
+	WriteString
 )
 
 type Instruction struct {
 	Name  InstructionType
 	Value int
+	// Offset is the distance from the current cell to the cell the instruction works on. MulAdd and Set use it for the
+	// cell they change, Increment, Decrement, Write, Read and Clear get one when the optimizer folds pointer moves into them
+	Offset int
+	// Text is the output written by WriteString
+	Text string
+	// Span is the source code this instruction was created from
+	Span Span
 }
 
 func (instruction *InstructionType) ToString() string {
@@ -44,6 +60,16 @@ func (instruction *InstructionType) ToString() string {
 		return "JumpUnlessZero"
 	case Clear:
 		return "Clear"
+	case MulAdd:
+		return "MulAdd"
+	case ScanRight:
+		return "ScanRight"
+	case ScanLeft:
+		return "ScanLeft"
+	case Set:
+		return "Set"
+	case WriteString:
+		return "WriteString"
 	case Unknown:
 		return "Unknown"
 	}
@@ -55,6 +81,22 @@ func (instruction *Instruction) IsJump() bool {
 	return instruction.Name == JumpIfZero || instruction.Name == JumpUnlessZero
 }
 
+// Opposite returns the instruction type undoing this instruction, Unknown when there is none
+func (instruction *Instruction) Opposite() InstructionType {
+	switch instruction.Name {
+	case MoveRight:
+		return MoveLeft
+	case MoveLeft:
+		return MoveRight
+	case Increment:
+		return Decrement
+	case Decrement:
+		return Increment
+	}
+
+	return Unknown
+}
+
 func (instruction *Instruction) CanBeOptimized() bool {
 	return instruction.Name == MoveRight ||
 		instruction.Name == MoveLeft ||
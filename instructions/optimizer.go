@@ -1,131 +1,715 @@
 package instructions
 
-type optimizedBlock struct {
-	startIndex int
-	length     int
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Stats describes what the optimizer changed
+type Stats struct {
+	// InstructionsBefore is the number of instructions before optimizing them
+	InstructionsBefore int `json:"instructions_before"`
+	// InstructionsAfter is the number of instructions left after every pass
+	InstructionsAfter int `json:"instructions_after"`
+	// DeadInstructions is the number of instructions removed as part of loops which can never be entered
+	DeadInstructions int `json:"dead_instructions"`
+	// Passes describes every pass which ran, in order
+	Passes []PassStats `json:"passes"`
 }
 
-var optimizedInstructions []Instruction
-var performedOptimizations []optimizedBlock
+// PassStats describes what a single pass changed
+type PassStats struct {
+	Name string `json:"name"`
+	// Changes is the number of changes made by the pass, what it counts is described by Unit
+	Changes int    `json:"changes"`
+	Unit    string `json:"unit"`
+	// Instructions is the number of instructions left after the pass
+	Instructions int           `json:"instructions"`
+	Duration     time.Duration `json:"duration_ns"`
+}
 
-func OptimizeInstructions(instructions []Instruction) []Instruction {
-	optimizedInstructions = make([]Instruction, 0)
-	performedOptimizations = make([]optimizedBlock, 0)
+// MaxLevel is the highest optimization level, running every pass
+const MaxLevel = 3
 
-	for instructionIndex := 0; instructionIndex < len(instructions); instructionIndex++ {
-		// Only try to optimize if we still have at least 2 instructions left to process
-		if instructionIndex > len(instructions)-2 {
-			optimizedInstructions = append(optimizedInstructions, instructions[instructionIndex:]...)
-			break
+// Pass is a single step of the Optimizer, replacing instructions with equivalent ones which run faster
+type Pass struct {
+	// Name identifies the pass, for example to disable it
+	Name string
+	// Level is the lowest optimization level running the pass
+	Level int
+	// Unit describes the changes counted by the pass, like "runs merged"
+	Unit string
+	// Run returns the optimized instructions. Jump instructions don't have to be linked in its result, the Optimizer
+	// links them after every pass instead of once at the end, since passes read the targets of jumps from their Value
+	Run func(instructions []Instruction) []Instruction
+}
+
+// Optimizer runs a pipeline of passes over instructions. It keeps no state outside of itself, separate optimizers can
+// be used concurrently
+type Optimizer struct {
+	passes   []Pass
+	disabled map[string]bool
+	stats    Stats
+	// changes counts the changes of the pass running
+	changes int
+	// onPass is called with the result of every pass
+	onPass func(pass string, instructions []Instruction)
+	// memorySize is the number of cells available to the program, 0 when it isn't known
+	memorySize int
+}
+
+// NewOptimizer returns an optimizer with every pass of this package registered and enabled
+func NewOptimizer() *Optimizer {
+	optimizer := &Optimizer{disabled: map[string]bool{}}
+
+	optimizer.Register(Pass{Name: "clear", Level: 1, Unit: "clears found", Run: optimizer.counted(optimizeClear)})
+	optimizer.Register(Pass{Name: "consecutive", Level: 1, Unit: "runs merged", Run: optimizer.counted(optimizeConsecutive)})
+	optimizer.Register(Pass{Name: "opposing", Level: 1, Unit: "instructions merged", Run: optimizer.counted(optimizeOpposing)})
+	optimizer.Register(Pass{Name: "dead-loops", Level: 2, Unit: "loops removed", Run: optimizer.counted(optimizer.optimizeDeadLoops)})
+	optimizer.Register(Pass{Name: "constants", Level: 3, Unit: "instructions evaluated", Run: optimizer.counted(optimizer.optimizeConstants)})
+	optimizer.Register(Pass{Name: "multiply", Level: 2, Unit: "loops replaced", Run: optimizer.counted(optimizeMultiplyLoops)})
+	optimizer.Register(Pass{Name: "scan", Level: 2, Unit: "loops replaced", Run: optimizer.counted(optimizeScanLoops)})
+	optimizer.Register(Pass{Name: "offsets", Level: 2, Unit: "moves folded", Run: optimizer.counted(optimizeOffsets)})
+
+	return optimizer
+}
+
+// counted adapts a pass which counts its changes, adding them to the changes of the pass running
+func (optimizer *Optimizer) counted(run func([]Instruction) ([]Instruction, int)) func([]Instruction) []Instruction {
+	return func(instructions []Instruction) []Instruction {
+		optimized, changes := run(instructions)
+		optimizer.changes += changes
+
+		return optimized
+	}
+}
+
+// Register appends an enabled pass to the end of the pipeline
+func (optimizer *Optimizer) Register(pass Pass) {
+	optimizer.passes = append(optimizer.passes, pass)
+}
+
+// OnPass sets a function which is called with the name and the result of every pass after it ran, for example to
+// inspect what the pass changed. The function must not modify the instructions
+func (optimizer *Optimizer) OnPass(onPass func(pass string, instructions []Instruction)) {
+	optimizer.onPass = onPass
+}
+
+// SetMemorySize sets the number of cells available to the program, passes evaluating the program at compile time stop
+// at the end of memory and leave the overflow for the engines to report
+func (optimizer *Optimizer) SetMemorySize(cells int) {
+	optimizer.memorySize = cells
+}
+
+// Passes returns the names of the registered passes, in the order they run
+func (optimizer *Optimizer) Passes() []string {
+	names := make([]string, 0, len(optimizer.passes))
+	for _, pass := range optimizer.passes {
+		names = append(names, pass.Name)
+	}
+
+	return names
+}
+
+// Enable enables the pass with the given name, it returns an error when no such pass is registered
+func (optimizer *Optimizer) Enable(name string) error {
+	if err := optimizer.checkPass(name); err != nil {
+		return err
+	}
+
+	delete(optimizer.disabled, name)
+
+	return nil
+}
+
+// Disable disables the pass with the given name, it returns an error when no such pass is registered
+func (optimizer *Optimizer) Disable(name string) error {
+	if err := optimizer.checkPass(name); err != nil {
+		return err
+	}
+
+	optimizer.disabled[name] = true
+
+	return nil
+}
+
+// SetLevel enables exactly the passes of an optimization level, from 0 without any passes to MaxLevel. Level 1 only
+// merges instructions next to each other, level 2 replaces loops and pointer moves and level 3 evaluates the start of
+// the program at compile time
+func (optimizer *Optimizer) SetLevel(level int) error {
+	if level < 0 || level > MaxLevel {
+		return fmt.Errorf("unsupported optimization level %d, must be 0 to %d", level, MaxLevel)
+	}
+
+	for _, pass := range optimizer.passes {
+		optimizer.disabled[pass.Name] = pass.Level > level
+	}
+
+	return nil
+}
+
+// SetPasses enables exactly the passes with the given names, it returns an error when one of them isn't registered
+func (optimizer *Optimizer) SetPasses(names []string) error {
+	for _, name := range names {
+		if err := optimizer.checkPass(name); err != nil {
+			return err
 		}
+	}
 
-		if optimizeClear(instructions, &instructionIndex) {
-			continue
+	for _, pass := range optimizer.passes {
+		optimizer.disabled[pass.Name] = true
+	}
+
+	for _, name := range names {
+		delete(optimizer.disabled, name)
+	}
+
+	return nil
+}
+
+// Enabled returns whether the pass with the given name is registered and enabled
+func (optimizer *Optimizer) Enabled(name string) bool {
+	return optimizer.checkPass(name) == nil && !optimizer.disabled[name]
+}
+
+func (optimizer *Optimizer) checkPass(name string) error {
+	for _, pass := range optimizer.passes {
+		if pass.Name == name {
+			return nil
 		}
+	}
+
+	return fmt.Errorf("unknown optimizer pass '%s', must be one of: %s", name, strings.Join(optimizer.Passes(), ", "))
+}
+
+// Optimize runs every enabled pass over instructions, in the order they were registered, and links the jump
+// instructions of the result of every pass. The instructions passed in aren't modified
+func (optimizer *Optimizer) Optimize(instructions []Instruction) []Instruction {
+	optimizer.stats = Stats{InstructionsBefore: len(instructions)}
 
-		if optimizeConsecutive(instructions, &instructionIndex) {
+	optimized := append([]Instruction(nil), instructions...)
+
+	for _, pass := range optimizer.passes {
+		if optimizer.disabled[pass.Name] {
 			continue
 		}
 
-		// If we couldn't find any optimizations, just append the instruction and try the next one
-		optimizedInstructions = append(optimizedInstructions, instructions[instructionIndex])
+		optimizer.changes = 0
+		start := time.Now()
+
+		optimized = pass.Run(optimized)
+		linkJumps(optimized)
+
+		optimizer.stats.Passes = append(optimizer.stats.Passes, PassStats{
+			Name:         pass.Name,
+			Changes:      optimizer.changes,
+			Unit:         pass.Unit,
+			Instructions: len(optimized),
+			Duration:     time.Since(start),
+		})
+
+		if optimizer.onPass != nil {
+			optimizer.onPass(pass.Name, optimized)
+		}
 	}
 
-	recalculateJumps(&optimizedInstructions, performedOptimizations)
+	optimizer.stats.InstructionsAfter = len(optimized)
 
-	return optimizedInstructions
+	return optimized
 }
 
-func optimizeClear(instructions []Instruction, instructionIndex *int) bool {
-	// Clear instruction optimization (needs at least 3 instructions)
-	if *instructionIndex > len(instructions)-2 {
-		return false
+// Stats describes what the last call to Optimize changed
+func (optimizer *Optimizer) Stats() Stats {
+	return optimizer.stats
+}
+
+// OptimizeInstructions optimizes instructions with every pass of a new Optimizer
+func OptimizeInstructions(instructions []Instruction) []Instruction {
+	return NewOptimizer().Optimize(instructions)
+}
+
+// optimizeClear replaces clear loops, [-], with a Clear instruction
+func optimizeClear(instructions []Instruction) ([]Instruction, int) {
+	optimized := make([]Instruction, 0, len(instructions))
+	clears := 0
+
+	for instructionIndex := 0; instructionIndex < len(instructions); instructionIndex++ {
+		instruction := instructions[instructionIndex]
+
+		// [-]
+		if instruction.Name == JumpIfZero && instructionIndex+2 < len(instructions) &&
+			instructions[instructionIndex+1].Name == Decrement &&
+			instructions[instructionIndex+2].Name == JumpUnlessZero {
+
+			instruction.Name = Clear
+			instruction.Span = mergeSpans(instructions[instructionIndex : instructionIndex+3])
+			instructionIndex += 2
+			clears++
+		}
+
+		optimized = append(optimized, instruction)
 	}
 
-	instruction := instructions[*instructionIndex]
+	return optimized, clears
+}
 
-	// [-]
-	if instruction.Name == JumpIfZero &&
-		instructions[*instructionIndex+1].Name == Decrement &&
-		instructions[*instructionIndex+2].Name == JumpUnlessZero {
+// optimizeConsecutive merges runs of the same instruction, like +++, into a single instruction with the length of the
+// run as its value
+func optimizeConsecutive(instructions []Instruction) ([]Instruction, int) {
+	optimized := make([]Instruction, 0, len(instructions))
+	runs := 0
 
-		instruction.Name = Clear
-		storeOptimization(instructionIndex, 2, instruction)
+	for instructionIndex := 0; instructionIndex < len(instructions); instructionIndex++ {
+		instruction := instructions[instructionIndex]
 
-		return true
+		// Only instructions that can be optimized are merged, loop until we find one that isn't the same, or we have
+		// reached the end of the instructions to process
+		recurringInstructions := 1
+		for instruction.CanBeOptimized() && instructionIndex+recurringInstructions < len(instructions) &&
+			instructions[instructionIndex+recurringInstructions].Name == instruction.Name {
+			recurringInstructions++
+		}
+
+		if recurringInstructions > 1 {
+			instruction.Value = recurringInstructions
+			instruction.Span = mergeSpans(instructions[instructionIndex : instructionIndex+recurringInstructions])
+			instructionIndex += recurringInstructions - 1
+			runs++
+		}
+
+		optimized = append(optimized, instruction)
 	}
 
-	return false
+	return optimized, runs
 }
 
-func optimizeConsecutive(instructions []Instruction, instructionIndex *int) bool {
-	instruction := instructions[*instructionIndex]
+// optimizeOpposing merges instructions with instructions of the same or opposite type next to them as signed
+// arithmetic, for example Increment(3), Decrement(2) becomes Increment(1). Instructions cancelling each other out are
+// removed, which can make more instructions meet, like in +><-
+func optimizeOpposing(instructions []Instruction) ([]Instruction, int) {
+	optimized := make([]Instruction, 0, len(instructions))
+	merged := 0
+
+	for _, instruction := range instructions {
+		if len(optimized) == 0 || !instruction.CanBeOptimized() {
+			optimized = append(optimized, instruction)
+			continue
+		}
+
+		previous := &optimized[len(optimized)-1]
+
+		switch previous.Name {
+		case instruction.Name:
+			previous.Value += instruction.Value
+		case instruction.Opposite():
+			previous.Value -= instruction.Value
+		default:
+			optimized = append(optimized, instruction)
+			continue
+		}
+
+		previous.Span = previous.Span.Merge(instruction.Span)
+		merged++
 
-	// Only try to optimize instructions that can be optimized
-	if !instruction.CanBeOptimized() {
-		return false
+		if previous.Value < 0 {
+			previous.Name = previous.Opposite()
+			previous.Value = -previous.Value
+		}
+
+		if previous.Value == 0 {
+			optimized = optimized[:len(optimized)-1]
+		}
 	}
 
-	// Check if we have at least one other instruction of the same type coming up
-	if instructions[*instructionIndex+1].Name != instruction.Name {
-		return false
+	return optimized, merged
+}
+
+// constantSteps is the number of instructions optimizeConstants evaluates at most, so programs which loop forever or
+// take long before reading input are still compiled quickly
+const constantSteps = 1 << 16
+
+// constantLimit is the limit of cell values during optimizeConstants, every cell size agrees on which values between
+// -constantLimit and constantLimit are zero and on their lowest byte
+const constantLimit = 256
+
+// constantCells is the number of cells optimizeConstants evaluates at most, so programs moving far to the right don't
+// use a lot of memory while compiling, even when the memory size isn't known
+const constantCells = 1 << 16
+
+// optimizeConstants evaluates the start of the program at compile time, where every cell is known to be zero, until
+// the first Read or until it takes too long. The evaluated instructions are replaced by a Set for every changed cell,
+// a WriteString with their output and a single pointer move. A loop which can't be evaluated completely is left alone
+func (optimizer *Optimizer) optimizeConstants(instructions []Instruction) ([]Instruction, int) {
+	cellLimit := constantCells
+	if optimizer.memorySize != 0 && optimizer.memorySize < cellLimit {
+		cellLimit = optimizer.memorySize
 	}
 
-	// Now we know what we have at least one more recurring instruction ahead,
-	// loop until we find one that isn't the same, or we have reached the end of the instructions to process
-	recurringInstructions := 1
-	for j := 0; true; j++ {
-		if *instructionIndex+j > len(instructions)-1 || instructions[*instructionIndex+j].Name != instruction.Name {
-			recurringInstructions = j
+	cells := []int{}
+	pointer := 0
+	var output []byte
+
+	// the state at the start of the outermost loop, which is restored when evaluation stops inside of it
+	loopStart := 0
+	var loopCells []int
+	loopPointer, loopOutput := 0, 0
+
+	// the instructions before evaluated have been evaluated completely
+	evaluated := 0
+	depth := 0
+	instructionIndex := 0
+
+evaluate:
+	for steps := 0; instructionIndex < len(instructions); instructionIndex, steps = instructionIndex+1, steps+1 {
+		instruction := instructions[instructionIndex]
+
+		if depth == 0 {
+			evaluated = instructionIndex
+		}
+
+		cell := pointer + instruction.Offset
+		if steps == constantSteps || cell < 0 || cell >= cellLimit {
 			break
 		}
+
+		for cell >= len(cells) {
+			cells = append(cells, 0)
+		}
+
+		switch instruction.Name {
+		case MoveRight:
+			pointer += instruction.Value
+		case MoveLeft:
+			if pointer-instruction.Value < 0 {
+				break evaluate
+			}
+
+			pointer -= instruction.Value
+		case Increment, Decrement:
+			value := cells[cell] + instruction.Value
+			if instruction.Name == Decrement {
+				value = cells[cell] - instruction.Value
+			}
+
+			if value <= -constantLimit || value >= constantLimit {
+				break evaluate
+			}
+
+			cells[cell] = value
+		case Write:
+			output = append(output, byte(cells[cell]))
+		case Clear:
+			cells[cell] = 0
+		case JumpIfZero:
+			if cells[cell] == 0 {
+				instructionIndex = instruction.Value
+				continue
+			}
+
+			if depth == 0 {
+				loopStart = instructionIndex
+				loopCells = append(loopCells[:0], cells...)
+				loopPointer, loopOutput = pointer, len(output)
+			}
+
+			depth++
+		case JumpUnlessZero:
+			if cells[cell] != 0 {
+				instructionIndex = instruction.Value
+				continue
+			}
+
+			depth--
+		default:
+			break evaluate
+		}
+	}
+
+	if depth > 0 {
+		// restore the state before the loop which couldn't be evaluated
+		evaluated = loopStart
+		cells, pointer, output = loopCells, loopPointer, output[:loopOutput]
+	} else if instructionIndex >= len(instructions) {
+		evaluated = len(instructions)
+	}
+
+	if evaluated == 0 {
+		return instructions, 0
+	}
+
+	span := mergeSpans(instructions[:evaluated])
+	optimized := make([]Instruction, 0, len(instructions)-evaluated)
+
+	for cell, value := range cells {
+		if value != 0 {
+			optimized = append(optimized, Instruction{Name: Set, Value: value, Offset: cell, Span: span})
+		}
 	}
 
-	instruction.Value = recurringInstructions
-	storeOptimization(instructionIndex, recurringInstructions-1, instruction)
+	if len(output) > 0 {
+		optimized = append(optimized, Instruction{Name: WriteString, Text: string(output), Span: span})
+	}
 
-	return true
+	optimized = appendMove(optimized, pointer, instructions[:evaluated])
+	optimized = append(optimized, instructions[evaluated:]...)
+
+	return optimized, evaluated
 }
 
-func storeOptimization(instructionIndex *int, length int, instruction Instruction) {
-	optimizedInstructions = append(optimizedInstructions, instruction)
+// optimizeDeadLoops removes loops which can never be entered, because the current cell is known to be zero when they
+// start: at the start of the program, where all cells are zero, like the comment loops at the top of many programs,
+// and right after a loop or a Clear. Returns the remaining instructions and the number of loops removed, the number of
+// instructions removed is added to the stats of the optimizer
+func (optimizer *Optimizer) optimizeDeadLoops(instructions []Instruction) ([]Instruction, int) {
+	optimized := make([]Instruction, 0, len(instructions))
+	removed, loops := 0, 0
+
+	// every cell is zero until the program changes one, the current cell is zero when allZero is
+	allZero := true
+	currentZero := true
 
-	// Store optimizations that have been to done to patch up jumps later
-	performedOptimizations = append(performedOptimizations, optimizedBlock{
-		startIndex: *instructionIndex,
-		length:     length,
-	})
+	for instructionIndex := 0; instructionIndex < len(instructions); instructionIndex++ {
+		instruction := instructions[instructionIndex]
+
+		switch instruction.Name {
+		case MoveRight, MoveLeft:
+			currentZero = allZero
+		case Increment, Decrement, Read, MulAdd:
+			allZero = false
+			if instruction.Name != MulAdd && instruction.Offset == 0 {
+				currentZero = false
+			}
+		case Set:
+			allZero = false
+			if instruction.Offset == 0 {
+				currentZero = instruction.Value == 0
+			}
+		case Clear:
+			if instruction.Offset == 0 {
+				currentZero = true
+			}
+		case ScanRight, ScanLeft, JumpUnlessZero:
+			currentZero = true
+		case JumpIfZero:
+			// the loop is skipped, and the current cell is still zero after it
+			if currentZero && instruction.Value > instructionIndex {
+				removed += instruction.Value - instructionIndex + 1
+				loops++
+				instructionIndex = instruction.Value
+				continue
+			}
 
-	*instructionIndex += length
+			// the loop is only entered when the current cell isn't zero
+			allZero = false
+			currentZero = false
+		}
+
+		optimized = append(optimized, instruction)
+	}
+
+	optimizer.stats.DeadInstructions += removed
+
+	return optimized, loops
 }
 
-func recalculateJumps(instructions *[]Instruction, performedOptimizations []optimizedBlock) {
-	recalculatedJumps := make(map[int]int)
+// optimizeMultiplyLoops replaces loops which decrement the current cell by one and add multiples of it to other cells,
+// for example [->+>++<<], with a MulAdd for every other cell followed by a Clear. The loop itself is kept, so the other
+// cells are only accessed when the current cell isn't zero, but it ends after a single iteration
+func optimizeMultiplyLoops(instructions []Instruction) ([]Instruction, int) {
+	optimized := make([]Instruction, 0, len(instructions))
+	loops := 0
 
-	for _, optimization := range performedOptimizations {
-		for instructionIndex := 0; instructionIndex < len(*instructions); instructionIndex++ {
-			instruction := &(*instructions)[instructionIndex]
+	for instructionIndex := 0; instructionIndex < len(instructions); instructionIndex++ {
+		instruction := instructions[instructionIndex]
 
-			if !instruction.IsJump() {
+		if instruction.Name == JumpIfZero && instruction.Value > instructionIndex {
+			if replacement, ok := multiplyLoop(instructions[instructionIndex : instruction.Value+1]); ok {
+				optimized = append(optimized, replacement...)
+				loops++
+				instructionIndex = instruction.Value
 				continue
 			}
+		}
+
+		optimized = append(optimized, instruction)
+	}
+
+	return optimized, loops
+}
+
+// multiplyLoop returns the instructions replacing a loop, including its jump instructions, when it is a multiply loop:
+// it only moves the pointer and changes cells, ends at the cell it started at and decrements that cell by one
+func multiplyLoop(loop []Instruction) ([]Instruction, bool) {
+	offset := 0
+	changes := map[int]int{}
 
-			if _, ok := recalculatedJumps[instructionIndex]; !ok {
-				recalculatedJumps[instructionIndex] = instruction.Value
+	for _, instruction := range loop[1 : len(loop)-1] {
+		switch instruction.Name {
+		case MoveRight:
+			offset += instruction.Value
+		case MoveLeft:
+			offset -= instruction.Value
+		case Increment:
+			changes[offset] += instruction.Value
+		case Decrement:
+			changes[offset] -= instruction.Value
+		default:
+			return nil, false
+		}
+	}
+
+	if offset != 0 || changes[0] != -1 {
+		return nil, false
+	}
+
+	offsets := make([]int, 0, len(changes))
+	for changedOffset, change := range changes {
+		if changedOffset != 0 && change != 0 {
+			offsets = append(offsets, changedOffset)
+		}
+	}
+
+	sort.Ints(offsets)
+
+	span := mergeSpans(loop)
+
+	// Without other cells to change, this is a clear loop like [-<>]
+	if len(offsets) == 0 {
+		return []Instruction{{Name: Clear, Span: span}}, true
+	}
+
+	replacement := []Instruction{loop[0]}
+	for _, changedOffset := range offsets {
+		replacement = append(replacement, Instruction{Name: MulAdd, Value: changes[changedOffset], Offset: changedOffset, Span: span})
+	}
+
+	return append(replacement, Instruction{Name: Clear, Span: span}, loop[len(loop)-1]), true
+}
+
+// optimizeScanLoops replaces loops which only move the pointer, for example [>] or [<<], with a ScanRight or ScanLeft
+func optimizeScanLoops(instructions []Instruction) ([]Instruction, int) {
+	optimized := make([]Instruction, 0, len(instructions))
+	loops := 0
+
+	for instructionIndex := 0; instructionIndex < len(instructions); instructionIndex++ {
+		instruction := instructions[instructionIndex]
+
+		if instruction.Name == JumpIfZero && instruction.Value == instructionIndex+2 {
+			move := instructions[instructionIndex+1]
+
+			scan := Unknown
+			switch move.Name {
+			case MoveRight:
+				scan = ScanRight
+			case MoveLeft:
+				scan = ScanLeft
+			}
+
+			if scan != Unknown && move.Value > 0 {
+				optimized = append(optimized, Instruction{
+					Name:  scan,
+					Value: move.Value,
+					Span:  mergeSpans(instructions[instructionIndex : instructionIndex+3]),
+				})
+				instructionIndex += 2
+				loops++
+				continue
 			}
+		}
 
-			// Fix jumps with links after offset
-			jumpLinksToInstructionAfterOffset := instruction.Value > optimization.startIndex && (optimization.startIndex+optimization.length) < instruction.Value
-			// Fix jumps that exist after offset that link back to everything before offset+length
-			jumpExistsAfterOffsetAndLinksBackBeforeOptimization := instructionIndex > optimization.startIndex && instruction.Value > optimization.startIndex
+		optimized = append(optimized, instruction)
+	}
 
-			if jumpLinksToInstructionAfterOffset || jumpExistsAfterOffsetAndLinksBackBeforeOptimization {
-				recalculatedJumps[instructionIndex] -= optimization.length
+	return optimized, loops
+}
+
+// optimizeOffsets folds pointer moves into the offsets of the instructions after them, for example >+>++<<- becomes
+// Increment(1) at offset 1, Increment(2) at offset 2 and Decrement(1) at offset 0. The pointer is only moved once, by
+// the net distance, at the end of every block of straight-line code: before jumps, scans and multiply instructions,
+// which work on the current cell, and at the end of the program
+func optimizeOffsets(instructions []Instruction) ([]Instruction, int) {
+	optimized := make([]Instruction, 0, len(instructions))
+
+	offset := 0
+	var moves []Instruction
+	folded := 0
+
+	for _, instruction := range instructions {
+		switch instruction.Name {
+		case MoveRight:
+			offset += instruction.Value
+			moves = append(moves, instruction)
+			folded++
+		case MoveLeft:
+			offset -= instruction.Value
+			moves = append(moves, instruction)
+			folded++
+		case Increment, Decrement, Write, Read, Clear, Set:
+			instruction.Offset += offset
+			optimized = append(optimized, instruction)
+		case WriteString:
+			optimized = append(optimized, instruction)
+		default:
+			optimized = appendMove(optimized, offset, moves)
+			offset, moves = 0, nil
+
+			optimized = append(optimized, instruction)
+		}
+	}
+
+	optimized = appendMove(optimized, offset, moves)
+
+	// every move which isn't left in the result has been folded into offsets
+	for _, instruction := range optimized {
+		if instruction.Name == MoveRight || instruction.Name == MoveLeft {
+			folded--
+		}
+	}
+
+	return optimized, folded
+}
+
+// appendMove appends a single instruction moving the pointer by offset, replacing moves
+func appendMove(instructions []Instruction, offset int, moves []Instruction) []Instruction {
+	switch {
+	case offset > 0:
+		return append(instructions, Instruction{Name: MoveRight, Value: offset, Span: mergeSpans(moves)})
+	case offset < 0:
+		return append(instructions, Instruction{Name: MoveLeft, Value: -offset, Span: mergeSpans(moves)})
+	}
+
+	return instructions
+}
+
+// linkJumps links every jump instruction to its matching jump instruction, after instructions have been added or removed
+func linkJumps(instructions []Instruction) {
+	var openJumps []int
+
+	for instructionIndex := range instructions {
+		switch instructions[instructionIndex].Name {
+		case JumpIfZero:
+			openJumps = append(openJumps, instructionIndex)
+		case JumpUnlessZero:
+			if len(openJumps) == 0 {
+				continue
 			}
+
+			linkedIndex := openJumps[len(openJumps)-1]
+			openJumps = openJumps[:len(openJumps)-1]
+
+			instructions[linkedIndex].Value = instructionIndex
+			instructions[instructionIndex].Value = linkedIndex
 		}
 	}
+}
 
-	for instructionIndex, newLink := range recalculatedJumps {
-		(*instructions)[instructionIndex].Value = newLink
+func mergeSpans(instructions []Instruction) Span {
+	span := Span{}
+	for _, instruction := range instructions {
+		span = span.Merge(instruction.Span)
 	}
+
+	return span
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestInstructions_Optimize(t *testing.T) {
@@ -25,11 +26,7 @@ func TestInstructions_Optimize(t *testing.T) {
 				{Name: MoveLeft, Value: 1},
 			},
 			[]Instruction{
-				{Name: Increment, Value: 3},
-				{Name: Decrement, Value: 2},
-				{Name: Increment, Value: 1},
-				{Name: MoveRight, Value: 2},
-				{Name: MoveLeft, Value: 2},
+				{Name: Increment, Value: 2},
 			},
 		},
 		{
@@ -115,27 +112,19 @@ func TestInstructions_Optimize(t *testing.T) {
 			[]Instruction{
 				{Name: Increment, Value: 13}, // 13
 
-				{Name: JumpIfZero, Value: 12},
+				{Name: JumpIfZero, Value: 8},
 
 				{Name: Decrement, Value: 0},
 
-				{Name: MoveRight, Value: 0},
-
 				{Name: Increment, Value: 2}, // 2
 
-				{Name: MoveRight, Value: 3}, // 3
-
-				{Name: Increment, Value: 5}, // 5
-
-				{Name: MoveRight, Value: 0},
-
-				{Name: Increment, Value: 2}, // 2
+				{Name: Increment, Value: 5, Offset: 3}, // 5
 
-				{Name: MoveRight, Value: 0},
+				{Name: Increment, Value: 2, Offset: 3}, // 2
 
-				{Name: Increment, Value: 0},
+				{Name: Increment, Value: 0, Offset: 3},
 
-				{Name: MoveLeft, Value: 6}, // 6
+				{Name: MoveLeft, Value: 3}, // 3 - 6
 
 				{Name: JumpUnlessZero, Value: 1},
 
@@ -156,9 +145,7 @@ func TestInstructions_Optimize(t *testing.T) {
 			},
 			[]Instruction{
 				{Name: Increment, Value: 2},
-				{Name: JumpIfZero, Value: 4},
-				{Name: Increment, Value: 2},
-				{Name: Decrement, Value: 2},
+				{Name: JumpIfZero, Value: 2},
 				{Name: JumpUnlessZero, Value: 1},
 				{Name: Increment, Value: 0},
 			},
@@ -184,9 +171,656 @@ func TestInstructions_Optimize(t *testing.T) {
 
 	for i, test := range tests {
 		t.Run(fmt.Sprintf("test_%d", i+1), func(t *testing.T) {
-			optimizedInstructions := OptimizeInstructions(test.instructions)
+			optimizedInstructions := OptimizeInstructions(afterRead(test.instructions))
+
+			assert.Equal(t, afterRead(test.expectedInstructions), optimizedInstructions)
+		})
+	}
+}
+
+// runPass runs a single pass like the Optimizer does, linking the jump instructions of its result
+func runPass(pass func([]Instruction) ([]Instruction, int), instructions []Instruction) []Instruction {
+	optimized, _ := pass(instructions)
+	linkJumps(optimized)
+
+	return optimized
+}
+
+// afterRead prepends a Read to instructions, so the optimizer can't evaluate the start of the program at compile time
+func afterRead(instructions []Instruction) []Instruction {
+	withRead := []Instruction{{Name: Read, Value: 1}}
+	for _, instruction := range instructions {
+		// Clear keeps the jump target of the loop it replaced
+		if instruction.IsJump() || instruction.Name == Clear {
+			instruction.Value++
+		}
+
+		withRead = append(withRead, instruction)
+	}
+
+	return withRead
+}
+
+func TestInstructions_OptimizeOpposing(t *testing.T) {
+	var tests = []struct {
+		input    []InstructionType
+		expected []Instruction
+	}{
+		// +++--
+		{[]InstructionType{Increment, Increment, Increment, Decrement, Decrement}, []Instruction{{Name: Increment, Value: 1}}},
+		// +--
+		{[]InstructionType{Increment, Decrement, Decrement}, []Instruction{{Name: Decrement, Value: 1}}},
+		// ><<<
+		{[]InstructionType{MoveRight, MoveLeft, MoveLeft, MoveLeft}, []Instruction{{Name: MoveLeft, Value: 2}}},
+		// <>+-
+		{[]InstructionType{MoveLeft, MoveRight, Increment, Decrement}, []Instruction{}},
+		// +><-
+		{[]InstructionType{Increment, MoveRight, MoveLeft, Decrement}, []Instruction{}},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test_%d", i+1), func(t *testing.T) {
+			input := make([]Instruction, len(test.input))
+			for i, name := range test.input {
+				input[i] = Instruction{Name: name, Value: 1}
+			}
+
+			assert.Equal(t, afterRead(test.expected), OptimizeInstructions(afterRead(input)))
+		})
+	}
+}
+
+func TestInstructions_OptimizeSpans(t *testing.T) {
+	column := func(offset int) Span {
+		return NewSpan(Position{Line: 1, Column: offset + 1, Offset: offset})
+	}
+
+	// ,++[-]>
+	optimizedInstructions := OptimizeInstructions(afterRead([]Instruction{
+		{Name: Increment, Value: 1, Span: column(0)},
+		{Name: Increment, Value: 1, Span: column(1)},
+		{Name: JumpIfZero, Value: 4, Span: column(2)},
+		{Name: Decrement, Value: 1, Span: column(3)},
+		{Name: JumpUnlessZero, Value: 2, Span: column(4)},
+		{Name: MoveRight, Value: 1, Span: column(5)},
+	}))
+
+	assert.Equal(t, []Span{
+		{Start: column(0).Start, End: column(1).End},
+		{Start: column(2).Start, End: column(4).End},
+		column(5),
+	}, []Span{optimizedInstructions[1].Span, optimizedInstructions[2].Span, optimizedInstructions[3].Span})
+
+	assert.Equal(t, "1:1-1:2", optimizedInstructions[1].Span.String())
+	assert.Equal(t, "1:6", optimizedInstructions[3].Span.String())
+}
+
+func TestInstructions_OptimizeMultiplyLoops(t *testing.T) {
+	var tests = []struct {
+		name                 string
+		instructions         []Instruction
+		expectedInstructions []Instruction
+	}{
+		{
+			"copy and multiply",
+			// [->+>+++<<]
+			[]Instruction{
+				{Name: JumpIfZero, Value: 7},
+				{Name: Decrement, Value: 1},
+				{Name: MoveRight, Value: 1},
+				{Name: Increment, Value: 1},
+				{Name: MoveRight, Value: 1},
+				{Name: Increment, Value: 3},
+				{Name: MoveLeft, Value: 2},
+				{Name: JumpUnlessZero, Value: 0},
+			},
+			[]Instruction{
+				{Name: JumpIfZero, Value: 4},
+				{Name: MulAdd, Value: 1, Offset: 1},
+				{Name: MulAdd, Value: 3, Offset: 2},
+				{Name: Clear},
+				{Name: JumpUnlessZero, Value: 0},
+			},
+		},
+		{
+			"negative offset and factor inside a loop",
+			// [>[-<-->]<]
+			[]Instruction{
+				{Name: JumpIfZero, Value: 9},
+				{Name: MoveRight, Value: 1},
+				{Name: JumpIfZero, Value: 7},
+				{Name: Decrement, Value: 1},
+				{Name: MoveLeft, Value: 1},
+				{Name: Decrement, Value: 2},
+				{Name: MoveRight, Value: 1},
+				{Name: JumpUnlessZero, Value: 2},
+				{Name: MoveLeft, Value: 1},
+				{Name: JumpUnlessZero, Value: 0},
+			},
+			[]Instruction{
+				{Name: JumpIfZero, Value: 7},
+				{Name: MoveRight, Value: 1},
+				{Name: JumpIfZero, Value: 5},
+				{Name: MulAdd, Value: -2, Offset: -1},
+				{Name: Clear},
+				{Name: JumpUnlessZero, Value: 2},
+				{Name: MoveLeft, Value: 1},
+				{Name: JumpUnlessZero, Value: 0},
+			},
+		},
+		{
+			"changes cancelling out",
+			// [->+<>-<]
+			[]Instruction{
+				{Name: JumpIfZero, Value: 8},
+				{Name: Decrement, Value: 1},
+				{Name: MoveRight, Value: 1},
+				{Name: Increment, Value: 1},
+				{Name: MoveLeft, Value: 1},
+				{Name: MoveRight, Value: 1},
+				{Name: Decrement, Value: 1},
+				{Name: MoveLeft, Value: 1},
+				{Name: JumpUnlessZero, Value: 0},
+			},
+			[]Instruction{
+				{Name: Clear},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			optimizedInstructions := runPass(optimizeMultiplyLoops, test.instructions)
+
+			// every instruction created from the loop has the span of the loop
+			for i := range optimizedInstructions {
+				optimizedInstructions[i].Span = Span{}
+			}
+
+			assert.Equal(t, test.expectedInstructions, optimizedInstructions)
+		})
+	}
+}
+
+func TestInstructions_OptimizeMultiplyLoopsUnchanged(t *testing.T) {
+	var tests = []struct {
+		name         string
+		instructions []Instruction
+	}{
+		{
+			"pointer moves",
+			// [->+]
+			[]Instruction{
+				{Name: JumpIfZero, Value: 4},
+				{Name: Decrement, Value: 1},
+				{Name: MoveRight, Value: 1},
+				{Name: Increment, Value: 1},
+				{Name: JumpUnlessZero, Value: 0},
+			},
+		},
+		{
+			"decrement by two",
+			// [-->+<]
+			[]Instruction{
+				{Name: JumpIfZero, Value: 5},
+				{Name: Decrement, Value: 2},
+				{Name: MoveRight, Value: 1},
+				{Name: Increment, Value: 1},
+				{Name: MoveLeft, Value: 1},
+				{Name: JumpUnlessZero, Value: 0},
+			},
+		},
+		{
+			"output",
+			// [->.<]
+			[]Instruction{
+				{Name: JumpIfZero, Value: 5},
+				{Name: Decrement, Value: 1},
+				{Name: MoveRight, Value: 1},
+				{Name: Write, Value: 1},
+				{Name: MoveLeft, Value: 1},
+				{Name: JumpUnlessZero, Value: 0},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.instructions, runPass(optimizeMultiplyLoops, test.instructions))
+		})
+	}
+}
+
+func TestInstructions_OptimizeScanLoops(t *testing.T) {
+	// +[>>]<[<]>[>.]
+	optimizedInstructions := runPass(optimizeScanLoops, []Instruction{
+		{Name: Increment, Value: 1},
+		{Name: JumpIfZero, Value: 3},
+		{Name: MoveRight, Value: 2},
+		{Name: JumpUnlessZero, Value: 1},
+		{Name: MoveLeft, Value: 1},
+		{Name: JumpIfZero, Value: 7},
+		{Name: MoveLeft, Value: 1},
+		{Name: JumpUnlessZero, Value: 5},
+		{Name: MoveRight, Value: 1},
+		{Name: JumpIfZero, Value: 12},
+		{Name: MoveRight, Value: 1},
+		{Name: Write, Value: 1},
+		{Name: JumpUnlessZero, Value: 9},
+	})
+
+	assert.Equal(t, []Instruction{
+		{Name: Increment, Value: 1},
+		{Name: ScanRight, Value: 2},
+		{Name: MoveLeft, Value: 1},
+		{Name: ScanLeft, Value: 1},
+		{Name: MoveRight, Value: 1},
+		{Name: JumpIfZero, Value: 8},
+		{Name: MoveRight, Value: 1},
+		{Name: Write, Value: 1},
+		{Name: JumpUnlessZero, Value: 5},
+	}, optimizedInstructions)
+}
+
+func TestInstructions_OptimizeOffsets(t *testing.T) {
+	// >+>++<<-[>.<,]>>
+	optimizedInstructions := runPass(optimizeOffsets, []Instruction{
+		{Name: MoveRight, Value: 1},
+		{Name: Increment, Value: 1},
+		{Name: MoveRight, Value: 1},
+		{Name: Increment, Value: 2},
+		{Name: MoveLeft, Value: 2},
+		{Name: Decrement, Value: 1},
+		{Name: JumpIfZero, Value: 12},
+		{Name: MoveRight, Value: 1},
+		{Name: Write, Value: 1},
+		{Name: MoveLeft, Value: 1},
+		{Name: Read, Value: 1},
+		{Name: MoveRight, Value: 3},
+		{Name: JumpUnlessZero, Value: 6},
+		{Name: MoveRight, Value: 2},
+	})
+
+	assert.Equal(t, []Instruction{
+		{Name: Increment, Value: 1, Offset: 1},
+		{Name: Increment, Value: 2, Offset: 2},
+		{Name: Decrement, Value: 1},
+		{Name: JumpIfZero, Value: 7},
+		{Name: Write, Value: 1, Offset: 1},
+		{Name: Read, Value: 1},
+		{Name: MoveRight, Value: 3},
+		{Name: JumpUnlessZero, Value: 3},
+		{Name: MoveRight, Value: 2},
+	}, optimizedInstructions)
+}
+
+func TestInstructions_OptimizeDeadLoops(t *testing.T) {
+	var tests = []struct {
+		instructions         []Instruction
+		expectedInstructions []Instruction
+		expectedRemoved      int
+	}{
+		{
+			// [+.]+[-][>]>[.]+
+			[]Instruction{
+				{Name: JumpIfZero, Value: 3},
+				{Name: Increment, Value: 1},
+				{Name: Write, Value: 1},
+				{Name: JumpUnlessZero, Value: 0},
+				{Name: Increment, Value: 1},
+				{Name: Clear},
+				{Name: JumpIfZero, Value: 8},
+				{Name: MoveRight, Value: 1},
+				{Name: JumpUnlessZero, Value: 6},
+				{Name: MoveRight, Value: 1},
+				{Name: JumpIfZero, Value: 12},
+				{Name: Write, Value: 1},
+				{Name: JumpUnlessZero, Value: 10},
+				{Name: Increment, Value: 1},
+			},
+			[]Instruction{
+				{Name: Increment, Value: 1},
+				{Name: Clear},
+				{Name: MoveRight, Value: 1},
+				{Name: JumpIfZero, Value: 5},
+				{Name: Write, Value: 1},
+				{Name: JumpUnlessZero, Value: 3},
+				{Name: Increment, Value: 1},
+			},
+			7,
+		},
+		{
+			// >>[.]+[>][.]
+			[]Instruction{
+				{Name: MoveRight, Value: 2},
+				{Name: JumpIfZero, Value: 3},
+				{Name: Write, Value: 1},
+				{Name: JumpUnlessZero, Value: 1},
+				{Name: Increment, Value: 1},
+				{Name: JumpIfZero, Value: 7},
+				{Name: MoveRight, Value: 1},
+				{Name: JumpUnlessZero, Value: 5},
+				{Name: JumpIfZero, Value: 10},
+				{Name: Write, Value: 1},
+				{Name: JumpUnlessZero, Value: 8},
+			},
+			[]Instruction{
+				{Name: MoveRight, Value: 2},
+				{Name: Increment, Value: 1},
+				{Name: JumpIfZero, Value: 4},
+				{Name: MoveRight, Value: 1},
+				{Name: JumpUnlessZero, Value: 2},
+			},
+			6,
+		},
+		{
+			// +>[.]
+			[]Instruction{
+				{Name: Increment, Value: 1},
+				{Name: MoveRight, Value: 1},
+				{Name: JumpIfZero, Value: 4},
+				{Name: Write, Value: 1},
+				{Name: JumpUnlessZero, Value: 2},
+			},
+			[]Instruction{
+				{Name: Increment, Value: 1},
+				{Name: MoveRight, Value: 1},
+				{Name: JumpIfZero, Value: 4},
+				{Name: Write, Value: 1},
+				{Name: JumpUnlessZero, Value: 2},
+			},
+			0,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test_%d", i+1), func(t *testing.T) {
+			optimizer := NewOptimizer()
+			optimizedInstructions := runPass(optimizer.optimizeDeadLoops, test.instructions)
 
 			assert.Equal(t, test.expectedInstructions, optimizedInstructions)
+			assert.Equal(t, test.expectedRemoved, optimizer.Stats().DeadInstructions)
 		})
 	}
 }
+
+func TestInstructions_OptimizeConstants(t *testing.T) {
+	var tests = []struct {
+		instructions         []Instruction
+		expectedInstructions []Instruction
+	}{
+		{
+			// ++++[>++<-]>.<+
+			[]Instruction{
+				{Name: Increment, Value: 4},
+				{Name: JumpIfZero, Value: 6},
+				{Name: MoveRight, Value: 1},
+				{Name: Increment, Value: 2},
+				{Name: MoveLeft, Value: 1},
+				{Name: Decrement, Value: 1},
+				{Name: JumpUnlessZero, Value: 1},
+				{Name: MoveRight, Value: 1},
+				{Name: Write, Value: 1},
+				{Name: MoveLeft, Value: 1},
+				{Name: Increment, Value: 1},
+			},
+			[]Instruction{
+				{Name: Set, Value: 1, Offset: 0},
+				{Name: Set, Value: 8, Offset: 1},
+				{Name: WriteString, Text: "\x08"},
+			},
+		},
+		{
+			// +>,[-]
+			[]Instruction{
+				{Name: Increment, Value: 1},
+				{Name: MoveRight, Value: 1},
+				{Name: Read, Value: 1},
+				{Name: JumpIfZero, Value: 5},
+				{Name: Decrement, Value: 1},
+				{Name: JumpUnlessZero, Value: 3},
+			},
+			[]Instruction{
+				{Name: Set, Value: 1, Offset: 0},
+				{Name: MoveRight, Value: 1},
+				{Name: Read, Value: 1},
+				{Name: JumpIfZero, Value: 5},
+				{Name: Decrement, Value: 1},
+				{Name: JumpUnlessZero, Value: 3},
+			},
+		},
+		{
+			// ++.[>+<+], the loop runs until the cell wraps and is kept
+			[]Instruction{
+				{Name: Increment, Value: 2},
+				{Name: Write, Value: 1},
+				{Name: JumpIfZero, Value: 7},
+				{Name: MoveRight, Value: 1},
+				{Name: Increment, Value: 1},
+				{Name: MoveLeft, Value: 1},
+				{Name: Increment, Value: 1},
+				{Name: JumpUnlessZero, Value: 2},
+			},
+			[]Instruction{
+				{Name: Set, Value: 2, Offset: 0},
+				{Name: WriteString, Text: "\x02"},
+				{Name: JumpIfZero, Value: 7},
+				{Name: MoveRight, Value: 1},
+				{Name: Increment, Value: 1},
+				{Name: MoveLeft, Value: 1},
+				{Name: Increment, Value: 1},
+				{Name: JumpUnlessZero, Value: 2},
+			},
+		},
+		{
+			// +<, the pointer underflow is left for the engines to report
+			[]Instruction{
+				{Name: Increment, Value: 1},
+				{Name: MoveLeft, Value: 1},
+			},
+			[]Instruction{
+				{Name: Set, Value: 1, Offset: 0},
+				{Name: MoveLeft, Value: 1},
+			},
+		},
+		{
+			// +[>>>...+], moving right forever stops at the last cell evaluated at compile time
+			[]Instruction{
+				{Name: Increment, Value: 1},
+				{Name: JumpIfZero, Value: 4},
+				{Name: MoveRight, Value: 20000},
+				{Name: Increment, Value: 1},
+				{Name: JumpUnlessZero, Value: 1},
+			},
+			[]Instruction{
+				{Name: Set, Value: 1, Offset: 0},
+				{Name: JumpIfZero, Value: 4},
+				{Name: MoveRight, Value: 20000},
+				{Name: Increment, Value: 1},
+				{Name: JumpUnlessZero, Value: 1},
+			},
+		},
+		{
+			// ,+
+			[]Instruction{
+				{Name: Read, Value: 1},
+				{Name: Increment, Value: 1},
+			},
+			[]Instruction{
+				{Name: Read, Value: 1},
+				{Name: Increment, Value: 1},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test_%d", i+1), func(t *testing.T) {
+			assert.Equal(t, test.expectedInstructions, runPass(NewOptimizer().optimizeConstants, test.instructions))
+		})
+	}
+}
+
+func TestInstructions_OptimizeConstantsMemorySize(t *testing.T) {
+	optimizer := NewOptimizer()
+	optimizer.SetMemorySize(100)
+
+	// +>>>...+, the overflow is left for the engines to report
+	optimized := runPass(optimizer.optimizeConstants, []Instruction{
+		{Name: Increment, Value: 1},
+		{Name: MoveRight, Value: 150},
+		{Name: Increment, Value: 1},
+	})
+
+	assert.Equal(t, []Instruction{
+		{Name: Set, Value: 1, Offset: 0},
+		{Name: MoveRight, Value: 150},
+		{Name: Increment, Value: 1},
+	}, optimized)
+}
+
+func TestInstructions_OptimizerPasses(t *testing.T) {
+	optimizer := NewOptimizer()
+	assert.Equal(t, []string{"clear", "consecutive", "opposing", "dead-loops", "constants", "multiply", "scan", "offsets"}, optimizer.Passes())
+
+	assert.EqualError(t, optimizer.Disable("unknown"), "unknown optimizer pass 'unknown', must be one of: clear, consecutive, opposing, dead-loops, constants, multiply, scan, offsets")
+	assert.Error(t, optimizer.Enable("unknown"))
+	assert.False(t, optimizer.Enabled("unknown"))
+
+	// ,[>]
+	input := []Instruction{
+		{Name: Read, Value: 1},
+		{Name: JumpIfZero, Value: 3},
+		{Name: MoveRight, Value: 1},
+		{Name: JumpUnlessZero, Value: 1},
+	}
+
+	assert.NoError(t, optimizer.Disable("scan"))
+	assert.False(t, optimizer.Enabled("scan"))
+	assert.Equal(t, input, optimizer.Optimize(input))
+
+	assert.NoError(t, optimizer.Enable("scan"))
+	assert.True(t, optimizer.Enabled("scan"))
+	assert.Equal(t, []Instruction{
+		{Name: Read, Value: 1},
+		{Name: ScanRight, Value: 1},
+	}, optimizer.Optimize(input))
+
+	assert.Equal(t, JumpIfZero, input[1].Name)
+}
+
+func TestInstructions_OptimizerRegister(t *testing.T) {
+	optimizer := NewOptimizer()
+	optimizer.Register(Pass{Name: "strip-writes", Run: func(instructions []Instruction) []Instruction {
+		stripped := make([]Instruction, 0, len(instructions))
+		for _, instruction := range instructions {
+			if instruction.Name != Write {
+				stripped = append(stripped, instruction)
+			}
+		}
+
+		return stripped
+	}})
+
+	assert.True(t, optimizer.Enabled("strip-writes"))
+
+	// ,[.>]
+	assert.Equal(t, []Instruction{
+		{Name: Read, Value: 1},
+		{Name: JumpIfZero, Value: 3},
+		{Name: MoveRight, Value: 1},
+		{Name: JumpUnlessZero, Value: 1},
+	}, optimizer.Optimize([]Instruction{
+		{Name: Read, Value: 1},
+		{Name: JumpIfZero, Value: 4},
+		{Name: Write, Value: 1},
+		{Name: MoveRight, Value: 1},
+		{Name: JumpUnlessZero, Value: 1},
+	}))
+}
+
+func TestInstructions_OptimizerLevels(t *testing.T) {
+	var tests = []struct {
+		level   int
+		enabled []string
+	}{
+		{0, []string{}},
+		{1, []string{"clear", "consecutive", "opposing"}},
+		{2, []string{"clear", "consecutive", "opposing", "dead-loops", "multiply", "scan", "offsets"}},
+		{3, []string{"clear", "consecutive", "opposing", "dead-loops", "constants", "multiply", "scan", "offsets"}},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("level_%d", test.level), func(t *testing.T) {
+			optimizer := NewOptimizer()
+			assert.NoError(t, optimizer.SetLevel(test.level))
+
+			enabled := []string{}
+			for _, name := range optimizer.Passes() {
+				if optimizer.Enabled(name) {
+					enabled = append(enabled, name)
+				}
+			}
+
+			assert.Equal(t, test.enabled, enabled)
+		})
+	}
+
+	assert.EqualError(t, NewOptimizer().SetLevel(4), "unsupported optimization level 4, must be 0 to 3")
+}
+
+func TestInstructions_OptimizerSetPasses(t *testing.T) {
+	optimizer := NewOptimizer()
+	assert.NoError(t, optimizer.SetPasses([]string{"scan", "clear"}))
+
+	for _, name := range optimizer.Passes() {
+		assert.Equal(t, name == "scan" || name == "clear", optimizer.Enabled(name), name)
+	}
+
+	assert.ErrorContains(t, optimizer.SetPasses([]string{"scan", "unknown"}), "unknown optimizer pass 'unknown'")
+	assert.True(t, optimizer.Enabled("scan"))
+	assert.False(t, optimizer.Enabled("offsets"))
+}
+
+func TestInstructions_OptimizerStats(t *testing.T) {
+	optimizer := NewOptimizer()
+
+	// ,[-]>>+++<[>]
+	optimized := optimizer.Optimize([]Instruction{
+		{Name: Read, Value: 1},
+		{Name: JumpIfZero, Value: 3},
+		{Name: Decrement, Value: 1},
+		{Name: JumpUnlessZero, Value: 1},
+		{Name: MoveRight, Value: 1},
+		{Name: MoveRight, Value: 1},
+		{Name: Increment, Value: 1},
+		{Name: Increment, Value: 1},
+		{Name: Increment, Value: 1},
+		{Name: MoveLeft, Value: 1},
+		{Name: JumpIfZero, Value: 12},
+		{Name: MoveRight, Value: 1},
+		{Name: JumpUnlessZero, Value: 10},
+	})
+
+	assert.Len(t, optimized, 5)
+
+	stats := optimizer.Stats()
+	for i := range stats.Passes {
+		assert.GreaterOrEqual(t, stats.Passes[i].Duration, time.Duration(0))
+		stats.Passes[i].Duration = 0
+	}
+
+	assert.Equal(t, Stats{
+		InstructionsBefore: 13,
+		InstructionsAfter:  5,
+		Passes: []PassStats{
+			{Name: "clear", Changes: 1, Unit: "clears found", Instructions: 11},
+			{Name: "consecutive", Changes: 2, Unit: "runs merged", Instructions: 8},
+			{Name: "opposing", Changes: 0, Unit: "instructions merged", Instructions: 8},
+			{Name: "dead-loops", Changes: 0, Unit: "loops removed", Instructions: 8},
+			{Name: "constants", Changes: 0, Unit: "instructions evaluated", Instructions: 8},
+			{Name: "multiply", Changes: 0, Unit: "loops replaced", Instructions: 8},
+			{Name: "scan", Changes: 1, Unit: "loops replaced", Instructions: 6},
+			{Name: "offsets", Changes: 1, Unit: "moves folded", Instructions: 5},
+		},
+	}, stats)
+}
@@ -0,0 +1,59 @@
+package instructions
+
+import "fmt"
+
+// Position is a location in the source code, Line and Column are 1-based and Column counts characters rather than bytes
+type Position struct {
+	Line   int
+	Column int
+	// Offset is the 0-based byte offset into the source code
+	Offset int
+}
+
+// Span is the range of source code an instruction was created from, both Start and End are inclusive
+type Span struct {
+	Start Position
+	End   Position
+}
+
+func NewSpan(position Position) Span {
+	return Span{position, position}
+}
+
+// IsZero reports whether the span is unknown, which is the case for instructions that weren't created by the parser
+func (span Span) IsZero() bool {
+	return span.Start.Line == 0
+}
+
+// Merge returns the smallest span covering both spans
+func (span Span) Merge(other Span) Span {
+	if span.IsZero() {
+		return other
+	}
+
+	if other.IsZero() {
+		return span
+	}
+
+	if other.Start.Offset < span.Start.Offset {
+		span.Start = other.Start
+	}
+
+	if other.End.Offset > span.End.Offset {
+		span.End = other.End
+	}
+
+	return span
+}
+
+func (position Position) String() string {
+	return fmt.Sprintf("%d:%d", position.Line, position.Column)
+}
+
+func (span Span) String() string {
+	if span.Start == span.End {
+		return span.Start.String()
+	}
+
+	return span.Start.String() + "-" + span.End.String()
+}
@@ -0,0 +1,233 @@
+package interpreter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"gobf/execution"
+	"gobf/instructions"
+	"io"
+)
+
+// cell is the type of a single memory cell, depending on the configured cell size
+type cell interface {
+	uint8 | uint16 | uint32
+}
+
+type Interpreter struct {
+	options      execution.Options
+	instructions []instructions.Instruction
+}
+
+func NewInterpreter(options execution.Options) *Interpreter {
+	return &Interpreter{
+		options:      options,
+		instructions: make([]instructions.Instruction, 0),
+	}
+}
+
+// Compile validates the instructions and stores them for execution, the interpreter runs them as-is
+func (interpreter *Interpreter) Compile(parsedInstructions []instructions.Instruction) error {
+	if err := interpreter.options.Validate(); err != nil {
+		return err
+	}
+
+	for _, instruction := range parsedInstructions {
+		if !instruction.IsJump() {
+			continue
+		}
+
+		if instruction.Value < 0 || instruction.Value >= len(parsedInstructions) {
+			return errors.New("failed to link jump instruction")
+		}
+	}
+
+	interpreter.instructions = parsedInstructions
+
+	return nil
+}
+
+// Run executes the instructions, reading input from input and writing output to output, until the program ends,
+// ctx is done or the step limit is exceeded
+func (interpreter *Interpreter) Run(ctx context.Context, input io.Reader, output io.Writer) error {
+	switch interpreter.options.CellBytes() {
+	case 2:
+		return run[uint16](ctx, interpreter, input, output)
+	case 4:
+		return run[uint32](ctx, interpreter, input, output)
+	}
+
+	return run[uint8](ctx, interpreter, input, output)
+}
+
+func run[T cell](ctx context.Context, interpreter *Interpreter, input io.Reader, output io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return execution.Canceled(err)
+	}
+
+	memory := make([]T, interpreter.options.MemorySize)
+	buffer := make([]byte, 1)
+	pointer := 0
+	var steps uint64
+
+	for programCounter := 0; programCounter < len(interpreter.instructions); programCounter++ {
+		instruction := &interpreter.instructions[programCounter]
+
+		// the cell the instruction works on, the current cell unless the optimizer gave it an offset
+		cell := pointer + instruction.Offset
+		if instruction.Offset != 0 {
+			if err := interpreter.checkBounds(programCounter, cell, len(memory)); err != nil {
+				return err
+			}
+		}
+
+		if err := checkAccess(instruction, cell, pointer, len(memory)); err != nil {
+			return err
+		}
+
+		switch instruction.Name {
+		case instructions.MoveRight:
+			pointer += instruction.Value
+			if err := interpreter.checkBounds(programCounter, pointer, len(memory)); err != nil {
+				return err
+			}
+		case instructions.MoveLeft:
+			pointer -= instruction.Value
+			if err := interpreter.checkBounds(programCounter, pointer, len(memory)); err != nil {
+				return err
+			}
+		case instructions.Increment:
+			memory[cell] += T(instruction.Value)
+		case instructions.Decrement:
+			memory[cell] -= T(instruction.Value)
+		case instructions.Write:
+			// Only the lowest 8 bits of a cell are written
+			buffer[0] = byte(memory[cell])
+			if _, err := output.Write(buffer); err != nil {
+				return errors.New("failed to write output: " + err.Error())
+			}
+		case instructions.Read:
+			if _, err := io.ReadFull(input, buffer); err != nil {
+				if err == io.EOF {
+					if interpreter.options.EOF != execution.EOFUnchanged {
+						memory[cell] = T(interpreter.options.EOFValue())
+					}
+
+					continue
+				}
+
+				return errors.New("failed to read input: " + err.Error())
+			}
+
+			memory[cell] = T(buffer[0])
+		case instructions.JumpIfZero:
+			// jump to the linked instruction, the loop increment moves us right after it
+			if memory[pointer] == 0 {
+				programCounter = instruction.Value
+			}
+		case instructions.JumpUnlessZero:
+			steps++
+			if err := interpreter.checkSteps(ctx, steps); err != nil {
+				return err
+			}
+
+			if memory[pointer] != 0 {
+				programCounter = instruction.Value
+			}
+		case instructions.Clear:
+			memory[cell] = 0
+		case instructions.MulAdd:
+			memory[cell] += memory[pointer] * T(instruction.Value)
+		case instructions.Set:
+			memory[cell] = T(instruction.Value)
+		case instructions.WriteString:
+			if _, err := io.WriteString(output, instruction.Text); err != nil {
+				return errors.New("failed to write output: " + err.Error())
+			}
+		case instructions.ScanRight:
+			pointer = scan(memory, pointer, instruction.Value)
+			if err := interpreter.checkBounds(programCounter, pointer, len(memory)); err != nil {
+				return err
+			}
+		case instructions.ScanLeft:
+			pointer = scan(memory, pointer, -instruction.Value)
+			if err := interpreter.checkBounds(programCounter, pointer, len(memory)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// scan moves the pointer by stride until it reaches a zero cell, or the first position outside of memory
+func scan[T cell](memory []T, pointer int, stride int) int {
+	// Byte cells are searched for a zero byte all at once, which is a lot faster than checking them one at a time
+	if memoryBytes, ok := any(memory).([]uint8); ok && pointer >= 0 && pointer < len(memory) {
+		switch stride {
+		case 1:
+			if index := bytes.IndexByte(memoryBytes[pointer:], 0); index >= 0 {
+				return pointer + index
+			}
+
+			return len(memory)
+		case -1:
+			// -1 when there is no zero byte, which is the position right before memory
+			return bytes.LastIndexByte(memoryBytes[:pointer+1], 0)
+		}
+	}
+
+	for pointer >= 0 && pointer < len(memory) && memory[pointer] != 0 {
+		pointer += stride
+	}
+
+	return pointer
+}
+
+// checkSteps fails when the step limit is exceeded, and checks whether ctx is done every CancelCheckInterval steps
+func (interpreter *Interpreter) checkSteps(ctx context.Context, steps uint64) error {
+	if interpreter.options.MaxSteps != 0 && steps > interpreter.options.MaxSteps {
+		return execution.ErrStepLimit
+	}
+
+	if steps%execution.CancelCheckInterval == 0 {
+		if err := ctx.Err(); err != nil {
+			return execution.Canceled(err)
+		}
+	}
+
+	return nil
+}
+
+// checkAccess fails with a TapeError when the instruction accesses a cell outside of memory. Without bounds checking
+// the pointer can move outside of memory, which is only an error once a cell outside of it is used
+func checkAccess(instruction *instructions.Instruction, cell int, pointer int, memorySize int) error {
+	switch instruction.Name {
+	case instructions.MoveRight, instructions.MoveLeft, instructions.WriteString, instructions.ScanRight, instructions.ScanLeft:
+		// scans check the pointer themselves while moving it
+		return nil
+	case instructions.MulAdd:
+		// reads the current cell as well as the cell at its offset
+		if pointer < 0 || pointer >= memorySize {
+			cell = pointer
+		}
+	}
+
+	if cell >= 0 && cell < memorySize {
+		return nil
+	}
+
+	return &execution.TapeError{Overflow: cell >= 0, Pointer: cell}
+}
+
+func (interpreter *Interpreter) checkBounds(programCounter int, pointer int, memorySize int) error {
+	if !interpreter.options.BoundsCheck || (pointer >= 0 && pointer < memorySize) {
+		return nil
+	}
+
+	return &execution.BoundsError{
+		Instruction: programCounter,
+		Pointer:     pointer,
+		Span:        interpreter.instructions[programCounter].Span,
+	}
+}
@@ -0,0 +1,256 @@
+package interpreter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"gobf/execution"
+	"gobf/instructions"
+	"gobf/parser"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInterpreter_Run(t *testing.T) {
+	var tests = []struct {
+		name   string
+		input  string
+		stdin  string
+		output string
+	}{
+		{"write", "++++++++[>++++++++<-]>+.", "", "A"},
+		{"wrap around", "-.+.", "", "\xff\x00"},
+		{"read", ",+.,+.", "ab", "bc"},
+		{"read eof leaves cell unchanged", "+++,.", "", "\x03"},
+		{"nested loops", "++[>++[>++<-]<-]>>.", "", "\x08"},
+		{"skip loop", "[.]+.", "", "\x01"},
+		{"multiply", ">+++++[-<+++++++++++++>>++<]<.>>.", "", "A\x0a"},
+		{"multiply negative", ">+++[-<-->]<.", "", "\xfa"},
+		// the loop is kept, so the cell before memory isn't accessed when the current cell is zero
+		{"skip multiply", "[-<+>]+.", "", "\x01"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			instructionParser := parser.NewParser()
+			parsedInstructions, err := instructionParser.Parse(test.input)
+			assert.NoError(t, err)
+
+			for _, optimize := range []bool{false, true} {
+				if optimize {
+					parsedInstructions = instructions.OptimizeInstructions(parsedInstructions)
+				}
+
+				output := &bytes.Buffer{}
+
+				interpreter := NewInterpreter(execution.Options{MemorySize: 100})
+
+				assert.NoError(t, interpreter.Compile(parsedInstructions))
+				assert.NoError(t, interpreter.Run(context.Background(), strings.NewReader(test.stdin), output))
+
+				assert.Equal(t, test.output, output.String())
+			}
+		})
+	}
+}
+
+func TestInterpreter_CompileInvalidJump(t *testing.T) {
+	interpreter := NewInterpreter(execution.Options{MemorySize: 100})
+	err := interpreter.Compile([]instructions.Instruction{
+		{Name: instructions.JumpIfZero, Value: 5},
+	})
+
+	assert.EqualError(t, err, "failed to link jump instruction")
+}
+
+func TestInterpreter_RunBoundsCheck(t *testing.T) {
+	var tests = []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"underflow", "+<", "pointer out of bounds at instruction 1: -1 (source 1:2)"},
+		{"overflow", ",>>>>[-]>", "pointer out of bounds at instruction 2: 5 (source 1:2-1:9)"},
+		{"optimized underflow", "><<<", "pointer out of bounds at instruction 0: -2 (source 1:1-1:4)"},
+		{"multiply", ",+[->>>>>+<<<<<]", "pointer out of bounds at instruction 3: 5 (source 1:3-1:16)"},
+		{"scan right", ",+>+>+>+>+<<[>]", "pointer out of bounds at instruction 7: 5 (source 1:13-1:15)"},
+		{"scan left", "+>+>+[<<]", "pointer out of bounds at instruction 4: -2 (source 1:6-1:9)"},
+		{"offset", ",+>>>>>+<<<<<", "pointer out of bounds at instruction 2: 5 (source 1:8)"},
+		{"offset read", "<,>", "pointer out of bounds at instruction 0: -1 (source 1:2)"},
+		{"constant", "+>>>>>+", "pointer out of bounds at instruction 1: 5 (source 1:1-1:7)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			instructionParser := parser.NewParser()
+			parsedInstructions, err := instructionParser.Parse(test.input)
+			assert.NoError(t, err)
+
+			parsedInstructions = instructions.OptimizeInstructions(parsedInstructions)
+
+			interpreter := NewInterpreter(execution.Options{MemorySize: 5, BoundsCheck: true})
+			assert.NoError(t, interpreter.Compile(parsedInstructions))
+
+			err = interpreter.Run(context.Background(), strings.NewReader(""), &bytes.Buffer{})
+
+			var boundsError *execution.BoundsError
+			assert.ErrorAs(t, err, &boundsError)
+			assert.EqualError(t, err, test.expected)
+		})
+	}
+}
+
+func TestInterpreter_RunTapeError(t *testing.T) {
+	var tests = []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"underflow", "<+.", "tape underflow: pointer -1 is before the start of memory"},
+		{"overflow", ",>>>>>.", "tape overflow: pointer 5 is past the end of memory"},
+		{"scan right", ",+>+>+>+>+<<<<[>]+", "tape overflow: pointer 5 is past the end of memory"},
+		{"scan left", ",+>+>+[<]+", "tape underflow: pointer -1 is before the start of memory"},
+		{"offset", ",>>>>>+<<<<<", "tape overflow: pointer 5 is past the end of memory"},
+		{"multiply", ",<+[->+<]", "tape underflow: pointer -1 is before the start of memory"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			instructionParser := parser.NewParser()
+			parsedInstructions, err := instructionParser.Parse(test.input)
+			assert.NoError(t, err)
+
+			for _, optimize := range []bool{false, true} {
+				if optimize {
+					parsedInstructions = instructions.OptimizeInstructions(parsedInstructions)
+				}
+
+				interpreter := NewInterpreter(execution.Options{MemorySize: 5})
+				assert.NoError(t, interpreter.Compile(parsedInstructions))
+
+				err = interpreter.Run(context.Background(), strings.NewReader(""), &bytes.Buffer{})
+
+				var tapeError *execution.TapeError
+				assert.ErrorAs(t, err, &tapeError)
+				assert.EqualError(t, err, test.expected)
+			}
+		})
+	}
+}
+
+func TestInterpreter_RunOutsideMemory(t *testing.T) {
+	// without bounds checking the pointer may leave memory, as long as no cell outside of it is used
+	interpreter := NewInterpreter(execution.Options{MemorySize: 5})
+
+	instructionParser := parser.NewParser()
+	parsedInstructions, err := instructionParser.Parse("<<>>+.")
+	assert.NoError(t, err)
+	assert.NoError(t, interpreter.Compile(parsedInstructions))
+
+	output := &bytes.Buffer{}
+	assert.NoError(t, interpreter.Run(context.Background(), strings.NewReader(""), output))
+	assert.Equal(t, "\x01", output.String())
+}
+
+func TestInterpreter_RunCellSizes(t *testing.T) {
+	// Prints 'Y' when the current cell is non-zero, 'N' otherwise
+	var nonZero = ">+<[>-<>>" + strings.Repeat("+", 'Y') + ".[-]<<[-]]>[->" + strings.Repeat("+", 'N') + ".[-]<]<"
+
+	var tests = []struct {
+		name     string
+		input    string
+		expected map[uint]string
+	}{
+		{"decrement wraps to maximum", "-.", map[uint]string{8: "\xff", 16: "\xff", 32: "\xff"}},
+		{"maximum wraps to zero", "-+" + nonZero, map[uint]string{8: "N", 16: "N", 32: "N"}},
+		{"wrap at 8 bits", strings.Repeat("+", 1<<8) + nonZero, map[uint]string{8: "N", 16: "Y", 32: "Y"}},
+		{"wrap at 16 bits", strings.Repeat("+", 1<<16) + nonZero, map[uint]string{8: "N", 16: "N", 32: "Y"}},
+		{"write lowest 8 bits", strings.Repeat("+", 1<<8+65) + ".", map[uint]string{8: "A", 16: "A", 32: "A"}},
+		{"read clears upper bits", "-," + strings.Repeat("-", 'a') + nonZero, map[uint]string{8: "N", 16: "N", 32: "N"}},
+	}
+
+	for _, test := range tests {
+		for cellSize, expected := range test.expected {
+			t.Run(fmt.Sprintf("%s %d", test.name, cellSize), func(t *testing.T) {
+				instructionParser := parser.NewParser()
+				parsedInstructions, err := instructionParser.Parse(test.input)
+				assert.NoError(t, err)
+
+				output := &bytes.Buffer{}
+
+				interpreter := NewInterpreter(execution.Options{MemorySize: 100, CellSize: cellSize})
+
+				assert.NoError(t, interpreter.Compile(instructions.OptimizeInstructions(parsedInstructions)))
+				assert.NoError(t, interpreter.Run(context.Background(), strings.NewReader("a"), output))
+
+				assert.Equal(t, expected, output.String())
+			})
+		}
+	}
+}
+
+func TestInterpreter_RunEOF(t *testing.T) {
+	var tests = []struct {
+		name     string
+		eof      execution.EOF
+		cellSize uint
+		input    string
+		expected string
+	}{
+		{"unchanged", execution.EOFUnchanged, 8, ",,.", "a"},
+		{"zero", execution.EOFZero, 8, ",,.", "\x00"},
+		{"minus-one", execution.EOFMinusOne, 8, ",,.", "\xff"},
+		{"minus-one fills the cell", execution.EOFMinusOne, 16, ",,+[>+.<[-]]", ""},
+		{"only on EOF", execution.EOFZero, 8, ",.,.", "a\x00"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			instructionParser := parser.NewParser()
+			parsedInstructions, err := instructionParser.Parse(test.input)
+			assert.NoError(t, err)
+
+			output := &bytes.Buffer{}
+
+			interpreter := NewInterpreter(execution.Options{MemorySize: 10, CellSize: test.cellSize, EOF: test.eof})
+
+			assert.NoError(t, interpreter.Compile(parsedInstructions))
+			assert.NoError(t, interpreter.Run(context.Background(), strings.NewReader("a"), output))
+
+			assert.Equal(t, test.expected, output.String())
+		})
+	}
+}
+
+func TestInterpreter_RunStepLimit(t *testing.T) {
+	instructionParser := parser.NewParser()
+	parsedInstructions, err := instructionParser.Parse("+++[-]")
+	assert.NoError(t, err)
+
+	// every iteration of the loop is a step
+	interpreter := NewInterpreter(execution.Options{MemorySize: 5, MaxSteps: 3})
+	assert.NoError(t, interpreter.Compile(parsedInstructions))
+	assert.NoError(t, interpreter.Run(context.Background(), strings.NewReader(""), &bytes.Buffer{}))
+
+	interpreter = NewInterpreter(execution.Options{MemorySize: 5, MaxSteps: 2})
+	assert.NoError(t, interpreter.Compile(parsedInstructions))
+	assert.ErrorIs(t, interpreter.Run(context.Background(), strings.NewReader(""), &bytes.Buffer{}), execution.ErrStepLimit)
+}
+
+func TestInterpreter_RunCanceled(t *testing.T) {
+	instructionParser := parser.NewParser()
+	parsedInstructions, err := instructionParser.Parse("+[]")
+	assert.NoError(t, err)
+
+	interpreter := NewInterpreter(execution.Options{MemorySize: 5})
+	assert.NoError(t, interpreter.Compile(parsedInstructions))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err = interpreter.Run(ctx, strings.NewReader(""), &bytes.Buffer{})
+	assert.ErrorIs(t, err, execution.ErrCanceled)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
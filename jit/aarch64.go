@@ -0,0 +1,663 @@
+package jit
+
+import (
+	"encoding/binary"
+	"errors"
+	"gobf/execution"
+	"gobf/instructions"
+)
+
+const (
+	OpcodeAdd = uint32(0x11000000)
+	OpcodeSub = uint32(0x51000000)
+	// 64-bit variants, used for the address counter so moving before the start of memory results in a negative offset
+	OpcodeAdd64 = uint32(0x91000000)
+	OpcodeSub64 = uint32(0xd1000000)
+	OpcodeCbz   = uint32(0x34000000)
+	OpcodeCbnz  = uint32(0x35000000)
+	OpcodeMovz  = uint32(0xd2800000)
+	OpcodeMovk  = uint32(0xf2800000)
+	OpcodeBeq   = uint32(0x54000000)
+	OpcodeAdr   = uint32(0x10000000)
+	OpcodeB     = uint32(0x14000000)
+	OpcodeBhs   = uint32(0x54000002)
+	OpcodeBls   = uint32(0x54000009)
+)
+
+func (jit *Jit) compileAarch64(parsedInstructions []instructions.Instruction) error {
+	// x0 contains a pointer to program memory
+	// x1 contains a pointer to the state shared with Go
+
+	// x9 = address counter
+	// x10 = step counter, returns to Go when it reaches 0
+	// x11 = scratch
+	// x12 = memory size, only used for bounds checking
+	// x13 = pointer to the state
+	// x14 = scratch
+	// x15 = pointer to program memory
+
+	// on return x0 contains the exit code, x1 the instruction index and x2 the address counter
+
+	if err := jit.checkOptions(); err != nil {
+		return err
+	}
+
+	var exitStubs []exitStub
+	var yieldStubs []yieldStub
+
+	jit.decode = decodeAarch64
+
+	jit.code = append(jit.code,
+		// reset registers x9, x10, x11 to 0
+		0x09, 0x00, 0x80, 0xd2, // mov x9, #0
+		0x0a, 0x00, 0x80, 0xd2, // mov x10, #0
+		0x0b, 0x00, 0x80, 0xd2, // mov x11, #0
+
+		// move first argument(pointer to program memory) to x15
+		0xef, 0x03, 0x00, 0xaa, // mov x15, x0
+
+		// move second argument(pointer to the state) to x13
+		0xed, 0x03, 0x01, 0xaa, // mov x13, x1
+	)
+
+	if jit.options.BoundsCheck {
+		jit.encodeAndAppendMoveImmediate(12, uint32(jit.options.MemorySize))
+	}
+
+	jit.code = append(jit.code,
+		// restore the address counter, which is 0 when the program starts
+		0xa9, 0x05, 0x40, 0xf9, // ldr x9, [x13, #8]
+
+		// restore the step counter
+		0xaa, 0x11, 0x40, 0xf9, // ldr x10, [x13, #32]
+
+		// continue where we returned to Go, unless the program starts
+		0xae, 0x01, 0x40, 0xf9, // ldr x14, [x13]
+		0x4e, 0x00, 0x00, 0xb4, // cbz x14, start
+		0xc0, 0x01, 0x1f, 0xd6, // br x14
+	)
+
+	for index, instruction := range parsedInstructions {
+		block := CodeBlock{
+			instruction: instruction,
+			offset:      len(jit.code),
+		}
+
+		// instructions working on the cell at an offset address it using x14, Read moves the address counter instead
+		if instruction.Offset != 0 && instruction.Name != instructions.Read {
+			if err := jit.appendAarch64OffsetAddress(instruction.Offset); err != nil {
+				return err
+			}
+
+			if jit.options.BoundsCheck {
+				exitStubs = append(exitStubs, jit.appendAarch64BoundsCheck(index, instruction.Offset))
+			}
+		}
+
+		switch instruction.Name {
+		case instructions.MoveRight:
+			// increase the address counter by one
+			if err := jit.encodeAndAppendMathInstruction(OpcodeAdd64, 9, instruction.Value); err != nil {
+				return err
+			}
+
+			if jit.options.BoundsCheck {
+				exitStubs = append(exitStubs, jit.appendAarch64BoundsCheck(index, 0))
+			}
+		case instructions.MoveLeft:
+			// decrease the address counter by instruction value
+			if err := jit.encodeAndAppendMathInstruction(OpcodeSub64, 9, instruction.Value); err != nil {
+				return err
+			}
+
+			if jit.options.BoundsCheck {
+				exitStubs = append(exitStubs, jit.appendAarch64BoundsCheck(index, 0))
+			}
+		case instructions.Increment:
+			// load the current value of the program memory offset by the address counter
+			jit.appendAarch64LoadCellAt(instruction.Offset) // ldrb w11, [x15, x9]
+
+			// add instruction value to the value which we've loaded, the store truncates it to the cell size
+			if err := jit.encodeAndAppendMathInstruction(OpcodeAdd, 11, int(uint32(instruction.Value)&jit.options.CellMask())); err != nil {
+				return err
+			}
+
+			// store the value back to the program memory including offset
+			jit.appendAarch64StoreCellAt(instruction.Offset) // strb w11, [x15, x9]
+		case instructions.Decrement:
+			// load the current value of the program memory offset by the address counter
+			jit.appendAarch64LoadCellAt(instruction.Offset) // ldrb w11, [x15, x9]
+
+			// subtract the instruction value from the value which we've loaded
+			if err := jit.encodeAndAppendMathInstruction(OpcodeSub, 11, int(uint32(instruction.Value)&jit.options.CellMask())); err != nil {
+				return err
+			}
+
+			// store the value back to the program memory including offset
+			jit.appendAarch64StoreCellAt(instruction.Offset) // strb w11, [x15, x9]
+		case instructions.Write:
+			if err := jit.appendAarch64BufferedWrite(instruction.Offset); err != nil {
+				return err
+			}
+		case instructions.Read:
+			// Go reads into the current cell, so move the address counter to the cell at the offset until it's done
+			if err := jit.appendAarch64AddressOffset(9, instruction.Offset); err != nil {
+				return err
+			}
+
+			if jit.options.BoundsCheck && instruction.Offset != 0 {
+				exitStubs = append(exitStubs, jit.appendAarch64BoundsCheck(index, 0))
+			}
+
+			// let Go read a byte into the current cell
+			jit.appendAarch64ReturnToGo(exitCodeRead)
+
+			// load the number of bytes read
+			jit.code = append(jit.code, 0xa0, 0x09, 0x40, 0xf9) // ldr x0, [x13, #16]
+
+			if jit.options.EOF != execution.EOFUnchanged {
+				if err := jit.appendAarch64ReadEOF(); err != nil {
+					return err
+				}
+			}
+
+			if jit.options.CellBytes() > 1 {
+				jit.appendAarch64ZeroExtendReadByte()
+			}
+
+			if err := jit.appendAarch64AddressOffset(9, -instruction.Offset); err != nil {
+				return err
+			}
+		case instructions.JumpIfZero:
+			// load the current value of the program memory offset by the address counter
+			jit.appendAarch64LoadCell() // ldrb w11, [x15, x9]
+
+			// jump to right before the linked jump instruction
+			jit.code = append(jit.code, 0x0, 0x0, 0x0, 0x0) // placeholder
+		case instructions.JumpUnlessZero:
+			// count the step, and let Go check whether the program may continue once the counter reaches 0
+			jit.code = append(jit.code, 0x4a, 0x05, 0x00, 0xf1) // subs x10, x10, #1
+			jit.code = append(jit.code, 0x0, 0x0, 0x0, 0x0)     // placeholder for b.eq
+
+			// kept out of the loop, so it only costs a branch which is almost never taken
+			yieldStubs = append(yieldStubs, yieldStub{offset: len(jit.code) - 4, instruction: index, resume: len(jit.code)})
+
+			// load the current value of the program memory offset by the address counter
+			jit.appendAarch64LoadCell() // ldrb w11, [x15, x9]
+
+			// jump to right after the linked jump instruction
+			jit.code = append(jit.code, 0x0, 0x0, 0x0, 0x0) // placeholder
+		case instructions.Clear:
+			// set up a zero value
+			jit.code = append(jit.code, 0x0b, 0x00, 0x80, 0x52) // mov w11, #0
+
+			// store the value back to the program memory including offset
+			jit.appendAarch64StoreCellAt(instruction.Offset) // strb w11, [x15, x9]
+		case instructions.MulAdd:
+			jit.appendAarch64MulAdd(instruction.Value)
+		case instructions.Set:
+			// set up the instruction value, the store truncates it to the cell size
+			jit.encodeAndAppendMoveImmediate(11, uint32(instruction.Value)&jit.options.CellMask()) // mov x11, #value
+
+			// store the value in the program memory including offset
+			jit.appendAarch64StoreCellAt(instruction.Offset) // strb w11, [x15, x9]
+		case instructions.WriteString:
+			// let Go write the text of this instruction
+			jit.encodeAndAppendMoveImmediate(1, uint32(index)) // mov x1, #index
+
+			jit.appendAarch64ReturnToGo(exitCodeWriteString)
+		case instructions.ScanRight, instructions.ScanLeft:
+			opcode := OpcodeAdd64
+			if instruction.Name == instructions.ScanLeft {
+				opcode = OpcodeSub64
+			}
+
+			// check the current cell first, the pointer only moves while it isn't zero
+			jump := len(jit.code)
+			jit.code = append(jit.code, 0x0, 0x0, 0x0, 0x0) // placeholder for b check
+			loop := len(jit.code)
+
+			if err := jit.encodeAndAppendMathInstruction(opcode, 9, instruction.Value); err != nil {
+				return err
+			}
+
+			if jit.options.BoundsCheck {
+				exitStubs = append(exitStubs, jit.appendAarch64BoundsCheck(index, 0))
+			}
+
+			// Encode imm26 (bits 25:0), the distance in instructions
+			binary.LittleEndian.PutUint32(jit.code[jump:], OpcodeB|uint32((len(jit.code)-jump)/4))
+
+			jit.appendAarch64LoadCell() // ldrb w11, [x15, x9]
+
+			opcode, err := encodeBranchInstruction(OpcodeCbnz, 11, loop-len(jit.code))
+			if err != nil {
+				return err
+			}
+
+			jit.code = binary.LittleEndian.AppendUint32(jit.code, opcode) // cbnz w11, loop
+		}
+
+		block.end = len(jit.code)
+		jit.codeBlocks = append(jit.codeBlocks, block)
+	}
+
+	jit.epilogue = len(jit.code)
+
+	jit.code = append(jit.code,
+		// return back to our Go program with a successful exit code
+		0x00, 0x00, 0x80, 0xd2, // mov x0, #0
+		0xc0, 0x03, 0x5f, 0xd6, // ret
+	)
+
+	for _, stub := range exitStubs {
+		start := len(jit.code)
+
+		opcode, err := encodeBranchInstruction(OpcodeBhs, 0, len(jit.code)-stub.offset)
+		if err != nil {
+			return err
+		}
+
+		binary.LittleEndian.PutUint32(jit.code[stub.offset:], opcode)
+
+		jit.encodeAndAppendMoveImmediate(0, exitCodeOutOfBounds)
+		jit.encodeAndAppendMoveImmediate(1, uint32(stub.instruction))
+
+		jit.code = append(jit.code, 0xe2, 0x03, 0x09, 0xaa) // mov x2, x9
+
+		if err := jit.appendAarch64AddressOffset(2, stub.pointerOffset); err != nil {
+			return err
+		}
+
+		jit.code = append(jit.code, 0xc0, 0x03, 0x5f, 0xd6) // ret
+
+		jit.stubs = append(jit.stubs, stubCode{"out of bounds", stub.instruction, start, len(jit.code)})
+	}
+
+	for _, stub := range yieldStubs {
+		start := len(jit.code)
+
+		opcode, err := encodeBranchInstruction(OpcodeBeq, 0, len(jit.code)-stub.offset)
+		if err != nil {
+			return err
+		}
+
+		binary.LittleEndian.PutUint32(jit.code[stub.offset:], opcode)
+
+		jit.appendAarch64ReturnToGoResumingAt(exitCodeYield, stub.resume)
+
+		jit.stubs = append(jit.stubs, stubCode{"yield", stub.instruction, start, len(jit.code)})
+	}
+
+	if err := jit.postProcessAarch64Jumps(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (jit *Jit) postProcessAarch64Jumps() error {
+	for i, block := range jit.codeBlocks {
+		if !block.instruction.IsJump() {
+			continue
+		}
+
+		jit.codeBlocks[i].link = &jit.codeBlocks[block.instruction.Value]
+	}
+
+	for _, block := range jit.codeBlocks {
+		// Only process jump instructions
+		if !block.instruction.IsJump() {
+			continue
+		}
+
+		// Only process linked blocks
+		if block.link == nil {
+			return errors.New("failed to link code block")
+		}
+
+		opcode := OpcodeCbz
+		if block.instruction.Name == instructions.JumpUnlessZero {
+			opcode = OpcodeCbnz
+		}
+
+		// Jump blocks end with their branch, which is relative to its own address, so +4 to branch to the end of
+		// the linked block
+		offset := block.link.end - block.end + 4
+
+		opcode, err := encodeBranchInstruction(opcode, 11, offset)
+		if err != nil {
+			return err
+		}
+
+		// the branch is the last instruction of the block
+		binary.LittleEndian.PutUint32(jit.code[block.end-4:], opcode)
+	}
+
+	return nil
+}
+
+func (jit *Jit) encodeAndAppendMathInstruction(opcode uint32, register int, immediate int) error {
+	return jit.encodeAndAppendMathInstructionFrom(opcode, register, register, immediate)
+}
+
+// encodeAndAppendMathInstructionFrom is encodeAndAppendMathInstruction storing the result of the source register in
+// the destination register
+func (jit *Jit) encodeAndAppendMathInstructionFrom(opcode uint32, destination int, source int, immediate int) error {
+	if immediate < 0 || immediate >= 1<<24 {
+		return errors.New("immediate out of range")
+	}
+
+	// Immediates are 12 bits, so larger ones are split into an instruction with the upper bits shifted left by 12
+	if immediate >= 1<<12 {
+		// Encode sh (bit 22) to shift imm12 left by 12 bits
+		if err := jit.encodeAndAppendMathInstructionFrom(opcode|1<<22, destination, source, immediate>>12); err != nil {
+			return err
+		}
+
+		immediate &= 0xFFF
+		if immediate == 0 {
+			return nil
+		}
+
+		source = destination
+	}
+
+	// Encode imm12 (bits 21:10)
+	opcode |= uint32(immediate) << 10
+
+	// Encode Rn (bits 9:5) and Rd (bits 4:0)
+	opcode |= uint32(source&0x1F) << 5
+	opcode |= uint32(destination & 0x1F)
+
+	jit.code = binary.LittleEndian.AppendUint32(jit.code, opcode)
+
+	return nil
+}
+
+// Loads and stores of w11 from and to the current cell, [x15, x9, lsl #log2(cellBytes)], by number of bytes per cell
+var aarch64LoadCell = map[int]uint32{
+	1: 0x386969eb, // ldrb w11, [x15, x9]
+	2: 0x786979eb, // ldrh w11, [x15, x9, lsl #1]
+	4: 0xb86979eb, // ldr w11, [x15, x9, lsl #2]
+}
+
+var aarch64StoreCell = map[int]uint32{
+	1: 0x382969eb, // strb w11, [x15, x9]
+	2: 0x782979eb, // strh w11, [x15, x9, lsl #1]
+	4: 0xb82979eb, // str w11, [x15, x9, lsl #2]
+}
+
+// Loads and stores of w11 from and to the cell addressed by x14, used for cells at an offset from the current cell
+var aarch64LoadCellAtOffset = map[int]uint32{
+	1: 0x386e69eb, // ldrb w11, [x15, x14]
+	2: 0x786e79eb, // ldrh w11, [x15, x14, lsl #1]
+	4: 0xb86e79eb, // ldr w11, [x15, x14, lsl #2]
+}
+
+var aarch64StoreCellAtOffset = map[int]uint32{
+	1: 0x382e69eb, // strb w11, [x15, x14]
+	2: 0x782e79eb, // strh w11, [x15, x14, lsl #1]
+	4: 0xb82e79eb, // str w11, [x15, x14, lsl #2]
+}
+
+// Loads and stores of w1 from and to the cell addressed by x14, used by MulAdd which keeps the current cell in w11
+var aarch64LoadOffsetCell = map[int]uint32{
+	1: 0x386e69e1, // ldrb w1, [x15, x14]
+	2: 0x786e79e1, // ldrh w1, [x15, x14, lsl #1]
+	4: 0xb86e79e1, // ldr w1, [x15, x14, lsl #2]
+}
+
+var aarch64StoreOffsetCell = map[int]uint32{
+	1: 0x382e69e1, // strb w1, [x15, x14]
+	2: 0x782e79e1, // strh w1, [x15, x14, lsl #1]
+	4: 0xb82e79e1, // str w1, [x15, x14, lsl #2]
+}
+
+func (jit *Jit) appendAarch64LoadCell() {
+	jit.code = binary.LittleEndian.AppendUint32(jit.code, aarch64LoadCell[jit.options.CellBytes()])
+}
+
+func (jit *Jit) appendAarch64StoreCell() {
+	jit.code = binary.LittleEndian.AppendUint32(jit.code, aarch64StoreCell[jit.options.CellBytes()])
+}
+
+// appendAarch64LoadCellAt loads the cell at offset from the current cell into w11, the address counter of the cell is
+// expected in x14 when offset isn't 0
+func (jit *Jit) appendAarch64LoadCellAt(offset int) {
+	if offset == 0 {
+		jit.appendAarch64LoadCell()
+		return
+	}
+
+	jit.code = binary.LittleEndian.AppendUint32(jit.code, aarch64LoadCellAtOffset[jit.options.CellBytes()])
+}
+
+// appendAarch64StoreCellAt stores w11 in the cell at offset from the current cell, the address counter of the cell is
+// expected in x14 when offset isn't 0
+func (jit *Jit) appendAarch64StoreCellAt(offset int) {
+	if offset == 0 {
+		jit.appendAarch64StoreCell()
+		return
+	}
+
+	jit.code = binary.LittleEndian.AppendUint32(jit.code, aarch64StoreCellAtOffset[jit.options.CellBytes()])
+}
+
+// appendAarch64OffsetAddress calculates the address counter of the cell at offset from the current cell into x14
+func (jit *Jit) appendAarch64OffsetAddress(offset int) error {
+	if offset < 0 {
+		return jit.encodeAndAppendMathInstructionFrom(OpcodeSub64, 14, 9, -offset) // sub x14, x9, #offset
+	}
+
+	return jit.encodeAndAppendMathInstructionFrom(OpcodeAdd64, 14, 9, offset) // add x14, x9, #offset
+}
+
+// appendAarch64MulAdd adds the current cell multiplied by factor to the cell addressed by x14
+func (jit *Jit) appendAarch64MulAdd(factor int) {
+	// load the current value of the program memory offset by the address counter
+	jit.appendAarch64LoadCell() // ldrb w11, [x15, x9]
+
+	// copies don't need a multiplication, the upper bits of the product are truncated by the store
+	if uint32(factor)&jit.options.CellMask() != 1 {
+		jit.encodeAndAppendMoveImmediate(0, uint32(factor)&jit.options.CellMask()) // mov x0, #factor
+		jit.code = append(jit.code, 0x6b, 0x7d, 0x00, 0x1b)                        // mul w11, w11, w0
+	}
+
+	jit.code = binary.LittleEndian.AppendUint32(jit.code, aarch64LoadOffsetCell[jit.options.CellBytes()])
+	jit.code = append(jit.code, 0x21, 0x00, 0x0b, 0x0b) // add w1, w1, w11
+	jit.code = binary.LittleEndian.AppendUint32(jit.code, aarch64StoreOffsetCell[jit.options.CellBytes()])
+}
+
+// appendAarch64AddressOffset adds a positive or negative offset to an address counter in register
+func (jit *Jit) appendAarch64AddressOffset(register int, offset int) error {
+	if offset < 0 {
+		return jit.encodeAndAppendMathInstruction(OpcodeSub64, register, -offset)
+	}
+
+	if offset > 0 {
+		return jit.encodeAndAppendMathInstruction(OpcodeAdd64, register, offset)
+	}
+
+	return nil
+}
+
+// aarch64CellShift returns the second byte of an add (shifted register) instruction, which holds the lower bits of
+// imm6 (bits 15:10) shifting the address counter left to convert it from cells to bytes
+func (jit *Jit) aarch64CellShift() byte {
+	switch jit.options.CellBytes() {
+	case 2:
+		return 0x04
+	case 4:
+		return 0x08
+	}
+
+	return 0x00
+}
+
+// appendAarch64ZeroExtendReadByte clears the upper bits of a cell after a byte has been read into its lowest byte,
+// the cell is left unchanged when nothing was read
+func (jit *Jit) appendAarch64ZeroExtendReadByte() {
+	store := uint32(0xb900002b) // str w11, [x1]
+	if jit.options.CellBytes() == 2 {
+		store = 0x7900002b // strh w11, [x1]
+	}
+
+	jit.code = append(jit.code,
+		// skip when Go didn't read exactly 1 byte
+		0x1f, 0x04, 0x00, 0xf1, // cmp x0, #1
+		0x81, 0x00, 0x00, 0x54, // b.ne #16
+
+		// calculate the address of the cell
+		0xe1, jit.aarch64CellShift()|0x01, 0x09, 0x8b, // add x1, x15, x9
+
+		// load the byte we've read, which zero extends it
+		0x2b, 0x00, 0x40, 0x39, // ldrb w11, [x1]
+	)
+
+	jit.code = binary.LittleEndian.AppendUint32(jit.code, store)
+}
+
+// appendAarch64BufferedWrite appends the lowest byte of the cell at offset from the current cell to the output buffer,
+// and lets Go flush the buffer once it is full, or right away when output is unbuffered
+func (jit *Jit) appendAarch64BufferedWrite(offset int) error {
+	// load the cell before x14 is used for the number of buffered bytes
+	jit.appendAarch64LoadCellAt(offset) // ldrb w11, [x15, x9]
+
+	// load the number of buffered bytes
+	jit.code = append(jit.code, 0xae, 0x0d, 0x40, 0xf9) // ldr x14, [x13, #24]
+
+	jit.code = append(jit.code,
+		// store the lowest byte of the current cell after the buffered bytes
+		0xa1, 0x01, 0x0e, 0x8b, // add x1, x13, x14
+		0x2b, 0xa0, 0x00, 0x39, // strb w11, [x1, #40]
+
+		// store the new number of buffered bytes
+		0xce, 0x05, 0x00, 0x91, // add x14, x14, #1
+		0xae, 0x0d, 0x00, 0xf9, // str x14, [x13, #24]
+	)
+
+	if jit.options.Unbuffered {
+		jit.appendAarch64ReturnToGo(exitCodeFlush)
+
+		return nil
+	}
+
+	// cmp x14, #(outputBufferSize - 1)
+	jit.code = binary.LittleEndian.AppendUint32(jit.code, 0xf10001df|uint32(outputBufferSize-1)<<10)
+
+	return jit.appendAarch64Skip(OpcodeBls, 0, func() { // b.ls skip
+		jit.appendAarch64ReturnToGo(exitCodeFlush)
+	})
+}
+
+// appendAarch64ReturnToGo saves the address counter, the step counter and the address to resume at, and returns to
+// Go with the exit code. The program continues right after it once Go calls the generated code again
+func (jit *Jit) appendAarch64ReturnToGo(exitCode uint32) {
+	// the resume address is right after the ret, 24 bytes further
+	jit.appendAarch64ReturnToGoResumingAt(exitCode, len(jit.code)+24)
+}
+
+// appendAarch64ReturnToGoResumingAt is appendAarch64ReturnToGo continuing at the resume offset in the generated code
+func (jit *Jit) appendAarch64ReturnToGoResumingAt(exitCode uint32, resume int) {
+	jit.code = append(jit.code,
+		0xa9, 0x05, 0x00, 0xf9, // str x9, [x13, #8]
+		0xaa, 0x11, 0x00, 0xf9, // str x10, [x13, #32]
+	)
+
+	// adr x14, resume, relative to the adr instruction itself. Encode immlo (bits 30:29) and immhi (bits 23:5)
+	offset := uint32(resume - len(jit.code))
+	jit.code = binary.LittleEndian.AppendUint32(jit.code, OpcodeAdr|(offset&0x3)<<29|(offset>>2&0x7FFFF)<<5|14)
+
+	jit.code = append(jit.code, 0xae, 0x01, 0x00, 0xf9) // str x14, [x13]
+
+	// exit codes always fit in 16 bits
+	jit.encodeAndAppendMoveImmediate(0, exitCode)
+
+	jit.code = append(jit.code, 0xc0, 0x03, 0x5f, 0xd6) // ret
+}
+
+// appendAarch64Skip appends a conditional branch with the given opcode and register, which skips the code appended
+// by body
+func (jit *Jit) appendAarch64Skip(opcode uint32, register int, body func()) error {
+	branch := len(jit.code)
+	jit.code = append(jit.code, 0x0, 0x0, 0x0, 0x0) // placeholder
+
+	body()
+
+	opcode, err := encodeBranchInstruction(opcode, register, len(jit.code)-branch)
+	if err != nil {
+		return err
+	}
+
+	binary.LittleEndian.PutUint32(jit.code[branch:], opcode)
+
+	return nil
+}
+
+// appendAarch64ReadEOF stores the configured EOF value in the current cell when Go didn't read exactly 1 byte
+func (jit *Jit) appendAarch64ReadEOF() error {
+	jit.code = append(jit.code, 0x1f, 0x04, 0x00, 0xf1) // cmp x0, #1
+
+	// skip the store when a byte was read
+	return jit.appendAarch64Skip(OpcodeBeq, 0, func() { // b.eq skip
+		jit.encodeAndAppendMoveImmediate(11, jit.options.EOFValue()) // mov x11, #value
+		jit.appendAarch64StoreCell()                                 // strb w11, [x15, x9]
+	})
+}
+
+// appendAarch64BoundsCheck compares the address counter with the memory size, and branches to an exit stub when it
+// is out of range. With a pointerOffset the address counter plus pointerOffset is compared, which is expected in x14
+func (jit *Jit) appendAarch64BoundsCheck(index int, pointerOffset int) exitStub {
+	// unsigned comparison, so a negative address counter is also out of range
+	if pointerOffset == 0 {
+		jit.code = append(jit.code, 0x3f, 0x01, 0x0c, 0xeb) // cmp x9, x12
+	} else {
+		jit.code = append(jit.code, 0xdf, 0x01, 0x0c, 0xeb) // cmp x14, x12
+	}
+
+	stub := exitStub{
+		offset:        len(jit.code),
+		instruction:   index,
+		pointerOffset: pointerOffset,
+	}
+
+	jit.code = append(jit.code, 0x0, 0x0, 0x0, 0x0) // placeholder for b.hs
+
+	return stub
+}
+
+// encodeAndAppendMoveImmediate moves a 32-bit immediate into a register, using a movk only when the immediate
+// doesn't fit in 16 bits
+func (jit *Jit) encodeAndAppendMoveImmediate(register int, immediate uint32) {
+	// Encode imm16 (bits 20:5) and Rd (bits 4:0)
+	jit.code = binary.LittleEndian.AppendUint32(jit.code, OpcodeMovz|(immediate&0xFFFF)<<5|uint32(register&0x1F))
+
+	if immediate > 0xFFFF {
+		// Encode hw (bits 22:21) to shift the immediate left by 16 bits
+		jit.code = binary.LittleEndian.AppendUint32(jit.code, OpcodeMovk|1<<21|(immediate>>16)<<5|uint32(register&0x1F))
+	}
+}
+
+func encodeBranchInstruction(opcode uint32, register int, offset int) (uint32, error) {
+	// Divide by 4 since instructions are always 4 bytes in length
+	offset /= 4
+
+	// Sign-extend the offset to 19 bits
+	if offset&(1<<18) != 0 { // If the 19th bit (sign bit) is set
+		offset |= ^((1 << 19) - 1) // Extend the sign bit to 32 bits
+	}
+
+	// Ensure the offset fits in a signed 19-bit integer (-2^18 to 2^18 - 1)
+	if offset < -(1<<18) || offset >= (1<<18) {
+		return 0, errors.New("offset is out of range for a jump")
+	}
+
+	// Encode the offset into bits [23:5]
+	opcode |= (uint32(offset) & 0x7FFFF) << 5
+
+	// Encode the register into bits [4:0]
+	opcode |= uint32(register & 0x1F)
+
+	return opcode, nil
+}
@@ -0,0 +1,348 @@
+package jit
+
+import (
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"gobf/execution"
+	"gobf/instructions"
+	"testing"
+)
+
+func TestJit_CompileAarch64(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.MoveRight},
+		{Name: instructions.MoveLeft},
+		{Name: instructions.Increment},
+		{Name: instructions.JumpIfZero},
+		{Name: instructions.Increment},
+		{Name: instructions.Decrement},
+		{Name: instructions.Increment},
+		{Name: instructions.JumpUnlessZero},
+		{Name: instructions.Read},
+		{Name: instructions.Write},
+		{Name: instructions.Clear},
+	}
+
+	jit := NewJit(execution.Options{MemorySize: 1000})
+	err := jit.compileAarch64(testInstructions)
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, []byte{
+		0x9, 0x0, 0x80, 0xd2, 0xa, 0x0, 0x80, 0xd2, 0xb, 0x0, 0x80, 0xd2, 0xef, 0x3, 0x0, 0xaa, 0xed, 0x3, 0x1,
+		0xaa, 0xa9, 0x5, 0x40, 0xf9, 0xaa, 0x11, 0x40, 0xf9, 0xae, 0x1, 0x40, 0xf9, 0x4e, 0x0, 0x0, 0xb4, 0xc0, 0x1,
+		0x1f, 0xd6, 0x29, 0x1, 0x0, 0x91, 0x29, 0x1, 0x0, 0xd1, 0xeb, 0x69, 0x69, 0x38, 0x6b, 0x1, 0x0, 0x11, 0xeb,
+		0x69, 0x29, 0x38, 0xeb, 0x69, 0x69, 0x38, 0x6b, 0xff, 0xff, 0x34, 0xeb, 0x69, 0x69, 0x38, 0x6b, 0x1, 0x0, 0x11,
+		0xeb, 0x69, 0x29, 0x38, 0xeb, 0x69, 0x69, 0x38, 0x6b, 0x1, 0x0, 0x51, 0xeb, 0x69, 0x29, 0x38, 0xeb, 0x69, 0x69,
+		0x38, 0x6b, 0x1, 0x0, 0x11, 0xeb, 0x69, 0x29, 0x38, 0x4a, 0x5, 0x0, 0xf1, 0x80, 0x3, 0x0, 0x54, 0xeb, 0x69,
+		0x69, 0x38, 0xcb, 0xfd, 0xff, 0x35, 0xa9, 0x5, 0x0, 0xf9, 0xaa, 0x11, 0x0, 0xf9, 0x8e, 0x0, 0x0, 0x10, 0xae,
+		0x1, 0x0, 0xf9, 0x60, 0x0, 0x80, 0xd2, 0xc0, 0x3, 0x5f, 0xd6, 0xa0, 0x9, 0x40, 0xf9, 0xeb, 0x69, 0x69, 0x38,
+		0xae, 0xd, 0x40, 0xf9, 0xa1, 0x1, 0xe, 0x8b, 0x2b, 0xa0, 0x0, 0x39, 0xce, 0x5, 0x0, 0x91, 0xae, 0xd, 0x0,
+		0xf9, 0xdf, 0xfd, 0x3f, 0xf1, 0xe9, 0x0, 0x0, 0x54, 0xa9, 0x5, 0x0, 0xf9, 0xaa, 0x11, 0x0, 0xf9, 0x8e, 0x0,
+		0x0, 0x10, 0xae, 0x1, 0x0, 0xf9, 0x40, 0x0, 0x80, 0xd2, 0xc0, 0x3, 0x5f, 0xd6, 0xb, 0x0, 0x80, 0x52, 0xeb,
+		0x69, 0x29, 0x38, 0x0, 0x0, 0x80, 0xd2, 0xc0, 0x3, 0x5f, 0xd6, 0xa9, 0x5, 0x0, 0xf9, 0xaa, 0x11, 0x0, 0xf9,
+		0x6e, 0xfc, 0xff, 0x10, 0xae, 0x1, 0x0, 0xf9, 0x80, 0x0, 0x80, 0xd2, 0xc0, 0x3, 0x5f, 0xd6,
+	}, jit.code)
+}
+
+func TestJit_CompileAarch64BoundsCheck(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.MoveLeft, Value: 1},
+		{Name: instructions.MoveRight, Value: 1},
+	}
+
+	jit := NewJit(execution.Options{MemorySize: 1000, BoundsCheck: true})
+	err := jit.compileAarch64(testInstructions)
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, []byte{
+		0x9, 0x0, 0x80, 0xd2, 0xa, 0x0, 0x80, 0xd2, 0xb, 0x0, 0x80, 0xd2, 0xef, 0x3, 0x0, 0xaa, 0xed, 0x3, 0x1,
+		0xaa, 0xc, 0x7d, 0x80, 0xd2, 0xa9, 0x5, 0x40, 0xf9, 0xaa, 0x11, 0x40, 0xf9, 0xae, 0x1, 0x40, 0xf9, 0x4e, 0x0,
+		0x0, 0xb4, 0xc0, 0x1, 0x1f, 0xd6, 0x29, 0x5, 0x0, 0xd1, 0x3f, 0x1, 0xc, 0xeb, 0xc2, 0x0, 0x0, 0x54, 0x29,
+		0x5, 0x0, 0x91, 0x3f, 0x1, 0xc, 0xeb, 0xe2, 0x0, 0x0, 0x54, 0x0, 0x0, 0x80, 0xd2, 0xc0, 0x3, 0x5f, 0xd6,
+		0x20, 0x0, 0x80, 0xd2, 0x1, 0x0, 0x80, 0xd2, 0xe2, 0x3, 0x9, 0xaa, 0xc0, 0x3, 0x5f, 0xd6, 0x20, 0x0, 0x80,
+		0xd2, 0x21, 0x0, 0x80, 0xd2, 0xe2, 0x3, 0x9, 0xaa, 0xc0, 0x3, 0x5f, 0xd6,
+	}, jit.code)
+}
+
+func TestJit_CompileAarch64MulAdd(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.MulAdd, Value: -2, Offset: -1},
+		{Name: instructions.MulAdd, Value: 1, Offset: 2},
+	}
+
+	jit := NewJit(execution.Options{MemorySize: 1000, CellSize: 16, BoundsCheck: true})
+	err := jit.compileAarch64(testInstructions)
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, []byte{
+		0x9, 0x0, 0x80, 0xd2, 0xa, 0x0, 0x80, 0xd2, 0xb, 0x0, 0x80, 0xd2, 0xef, 0x3, 0x0, 0xaa, 0xed, 0x3, 0x1,
+		0xaa, 0xc, 0x7d, 0x80, 0xd2, 0xa9, 0x5, 0x40, 0xf9, 0xaa, 0x11, 0x40, 0xf9, 0xae, 0x1, 0x40, 0xf9, 0x4e, 0x0,
+		0x0, 0xb4, 0xc0, 0x1, 0x1f, 0xd6, 0x2e, 0x5, 0x0, 0xd1, 0xdf, 0x1, 0xc, 0xeb, 0x2, 0x2, 0x0, 0x54, 0xeb,
+		0x79, 0x69, 0x78, 0xc0, 0xff, 0x9f, 0xd2, 0x6b, 0x7d, 0x0, 0x1b, 0xe1, 0x79, 0x6e, 0x78, 0x21, 0x0, 0xb, 0xb,
+		0xe1, 0x79, 0x2e, 0x78, 0x2e, 0x9, 0x0, 0x91, 0xdf, 0x1, 0xc, 0xeb, 0x82, 0x1, 0x0, 0x54, 0xeb, 0x79, 0x69,
+		0x78, 0xe1, 0x79, 0x6e, 0x78, 0x21, 0x0, 0xb, 0xb, 0xe1, 0x79, 0x2e, 0x78, 0x0, 0x0, 0x80, 0xd2, 0xc0, 0x3,
+		0x5f, 0xd6, 0x20, 0x0, 0x80, 0xd2, 0x1, 0x0, 0x80, 0xd2, 0xe2, 0x3, 0x9, 0xaa, 0x42, 0x4, 0x0, 0xd1, 0xc0,
+		0x3, 0x5f, 0xd6, 0x20, 0x0, 0x80, 0xd2, 0x21, 0x0, 0x80, 0xd2, 0xe2, 0x3, 0x9, 0xaa, 0x42, 0x8, 0x0, 0x91,
+		0xc0, 0x3, 0x5f, 0xd6,
+	}, jit.code)
+}
+
+func TestJit_CompileAarch64Offsets(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.Increment, Value: 1, Offset: 1},
+		{Name: instructions.Decrement, Value: 2, Offset: -100},
+		{Name: instructions.Write, Value: 1, Offset: 2},
+		{Name: instructions.Read, Value: 1, Offset: -1},
+		{Name: instructions.Clear, Offset: 3},
+	}
+
+	jit := NewJit(execution.Options{MemorySize: 1000, CellSize: 16, BoundsCheck: true})
+	err := jit.compileAarch64(testInstructions)
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, []byte{
+		0x9, 0x0, 0x80, 0xd2, 0xa, 0x0, 0x80, 0xd2, 0xb, 0x0, 0x80, 0xd2, 0xef, 0x3, 0x0, 0xaa, 0xed, 0x3, 0x1,
+		0xaa, 0xc, 0x7d, 0x80, 0xd2, 0xa9, 0x5, 0x40, 0xf9, 0xaa, 0x11, 0x40, 0xf9, 0xae, 0x1, 0x40, 0xf9, 0x4e, 0x0,
+		0x0, 0xb4, 0xc0, 0x1, 0x1f, 0xd6, 0x2e, 0x5, 0x0, 0x91, 0xdf, 0x1, 0xc, 0xeb, 0x42, 0x6, 0x0, 0x54, 0xeb,
+		0x79, 0x6e, 0x78, 0x6b, 0x5, 0x0, 0x11, 0xeb, 0x79, 0x2e, 0x78, 0x2e, 0x91, 0x1, 0xd1, 0xdf, 0x1, 0xc, 0xeb,
+		0x22, 0x6, 0x0, 0x54, 0xeb, 0x79, 0x6e, 0x78, 0x6b, 0x9, 0x0, 0x51, 0xeb, 0x79, 0x2e, 0x78, 0x2e, 0x9, 0x0,
+		0x91, 0xdf, 0x1, 0xc, 0xeb, 0x2, 0x6, 0x0, 0x54, 0xeb, 0x79, 0x6e, 0x78, 0xae, 0xd, 0x40, 0xf9, 0xa1, 0x1,
+		0xe, 0x8b, 0x2b, 0xa0, 0x0, 0x39, 0xce, 0x5, 0x0, 0x91, 0xae, 0xd, 0x0, 0xf9, 0xdf, 0xfd, 0x3f, 0xf1, 0xe9,
+		0x0, 0x0, 0x54, 0xa9, 0x5, 0x0, 0xf9, 0xaa, 0x11, 0x0, 0xf9, 0x8e, 0x0, 0x0, 0x10, 0xae, 0x1, 0x0, 0xf9,
+		0x40, 0x0, 0x80, 0xd2, 0xc0, 0x3, 0x5f, 0xd6, 0x29, 0x5, 0x0, 0xd1, 0x3f, 0x1, 0xc, 0xeb, 0x82, 0x4, 0x0,
+		0x54, 0xa9, 0x5, 0x0, 0xf9, 0xaa, 0x11, 0x0, 0xf9, 0x8e, 0x0, 0x0, 0x10, 0xae, 0x1, 0x0, 0xf9, 0x60, 0x0,
+		0x80, 0xd2, 0xc0, 0x3, 0x5f, 0xd6, 0xa0, 0x9, 0x40, 0xf9, 0x1f, 0x4, 0x0, 0xf1, 0x81, 0x0, 0x0, 0x54, 0xe1,
+		0x5, 0x9, 0x8b, 0x2b, 0x0, 0x40, 0x39, 0x2b, 0x0, 0x0, 0x79, 0x29, 0x5, 0x0, 0x91, 0x2e, 0xd, 0x0, 0x91,
+		0xdf, 0x1, 0xc, 0xeb, 0x2, 0x3, 0x0, 0x54, 0xb, 0x0, 0x80, 0x52, 0xeb, 0x79, 0x2e, 0x78, 0x0, 0x0, 0x80,
+		0xd2, 0xc0, 0x3, 0x5f, 0xd6, 0x20, 0x0, 0x80, 0xd2, 0x1, 0x0, 0x80, 0xd2, 0xe2, 0x3, 0x9, 0xaa, 0x42, 0x4,
+		0x0, 0x91, 0xc0, 0x3, 0x5f, 0xd6, 0x20, 0x0, 0x80, 0xd2, 0x21, 0x0, 0x80, 0xd2, 0xe2, 0x3, 0x9, 0xaa, 0x42,
+		0x90, 0x1, 0xd1, 0xc0, 0x3, 0x5f, 0xd6, 0x20, 0x0, 0x80, 0xd2, 0x41, 0x0, 0x80, 0xd2, 0xe2, 0x3, 0x9, 0xaa,
+		0x42, 0x8, 0x0, 0x91, 0xc0, 0x3, 0x5f, 0xd6, 0x20, 0x0, 0x80, 0xd2, 0x61, 0x0, 0x80, 0xd2, 0xe2, 0x3, 0x9,
+		0xaa, 0xc0, 0x3, 0x5f, 0xd6, 0x20, 0x0, 0x80, 0xd2, 0x81, 0x0, 0x80, 0xd2, 0xe2, 0x3, 0x9, 0xaa, 0x42, 0xc,
+		0x0, 0x91, 0xc0, 0x3, 0x5f, 0xd6,
+	}, jit.code)
+}
+
+func TestJit_CompileAarch64Constants(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.Set, Value: 72, Offset: 1},
+		{Name: instructions.Set, Value: -1},
+		{Name: instructions.WriteString, Text: "Hi"},
+	}
+
+	jit := NewJit(execution.Options{MemorySize: 1000, CellSize: 16, BoundsCheck: true})
+	err := jit.compileAarch64(testInstructions)
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, []byte{
+		0x9, 0x0, 0x80, 0xd2, 0xa, 0x0, 0x80, 0xd2, 0xb, 0x0, 0x80, 0xd2, 0xef, 0x3, 0x0, 0xaa, 0xed, 0x3, 0x1,
+		0xaa, 0xc, 0x7d, 0x80, 0xd2, 0xa9, 0x5, 0x40, 0xf9, 0xaa, 0x11, 0x40, 0xf9, 0xae, 0x1, 0x40, 0xf9, 0x4e, 0x0,
+		0x0, 0xb4, 0xc0, 0x1, 0x1f, 0xd6, 0x2e, 0x5, 0x0, 0x91, 0xdf, 0x1, 0xc, 0xeb, 0xc2, 0x1, 0x0, 0x54, 0xb,
+		0x9, 0x80, 0xd2, 0xeb, 0x79, 0x2e, 0x78, 0xeb, 0xff, 0x9f, 0xd2, 0xeb, 0x79, 0x29, 0x78, 0x41, 0x0, 0x80, 0xd2,
+		0xa9, 0x5, 0x0, 0xf9, 0xaa, 0x11, 0x0, 0xf9, 0x8e, 0x0, 0x0, 0x10, 0xae, 0x1, 0x0, 0xf9, 0xa0, 0x0, 0x80,
+		0xd2, 0xc0, 0x3, 0x5f, 0xd6, 0x0, 0x0, 0x80, 0xd2, 0xc0, 0x3, 0x5f, 0xd6, 0x20, 0x0, 0x80, 0xd2, 0x1, 0x0,
+		0x80, 0xd2, 0xe2, 0x3, 0x9, 0xaa, 0x42, 0x4, 0x0, 0x91, 0xc0, 0x3, 0x5f, 0xd6,
+	}, jit.code)
+}
+
+func TestJit_CompileAarch64Scan(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.ScanRight, Value: 1},
+		{Name: instructions.ScanLeft, Value: 3},
+	}
+
+	jit := NewJit(execution.Options{MemorySize: 1000, BoundsCheck: true})
+	err := jit.compileAarch64(testInstructions)
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, []byte{
+		0x9, 0x0, 0x80, 0xd2, 0xa, 0x0, 0x80, 0xd2, 0xb, 0x0, 0x80, 0xd2, 0xef, 0x3, 0x0, 0xaa, 0xed, 0x3, 0x1,
+		0xaa, 0xc, 0x7d, 0x80, 0xd2, 0xa9, 0x5, 0x40, 0xf9, 0xaa, 0x11, 0x40, 0xf9, 0xae, 0x1, 0x40, 0xf9, 0x4e, 0x0,
+		0x0, 0xb4, 0xc0, 0x1, 0x1f, 0xd6, 0x4, 0x0, 0x0, 0x14, 0x29, 0x5, 0x0, 0x91, 0x3f, 0x1, 0xc, 0xeb, 0x62,
+		0x1, 0x0, 0x54, 0xeb, 0x69, 0x69, 0x38, 0x8b, 0xff, 0xff, 0x35, 0x4, 0x0, 0x0, 0x14, 0x29, 0xd, 0x0, 0xd1,
+		0x3f, 0x1, 0xc, 0xeb, 0x22, 0x1, 0x0, 0x54, 0xeb, 0x69, 0x69, 0x38, 0x8b, 0xff, 0xff, 0x35, 0x0, 0x0, 0x80,
+		0xd2, 0xc0, 0x3, 0x5f, 0xd6, 0x20, 0x0, 0x80, 0xd2, 0x1, 0x0, 0x80, 0xd2, 0xe2, 0x3, 0x9, 0xaa, 0xc0, 0x3,
+		0x5f, 0xd6, 0x20, 0x0, 0x80, 0xd2, 0x21, 0x0, 0x80, 0xd2, 0xe2, 0x3, 0x9, 0xaa, 0xc0, 0x3, 0x5f, 0xd6,
+	}, jit.code)
+}
+
+func TestJit_CompileAarch64CellSizes(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.Increment, Value: 0x10001},
+		{Name: instructions.Read, Value: 1},
+		{Name: instructions.JumpIfZero, Value: 3},
+		{Name: instructions.JumpUnlessZero, Value: 2},
+	}
+
+	var tests = []struct {
+		name     string
+		cellSize uint
+		expected []byte
+	}{
+		{
+			"16-bit",
+			16,
+			[]byte{
+				0x9, 0x0, 0x80, 0xd2, 0xa, 0x0, 0x80, 0xd2, 0xb, 0x0, 0x80, 0xd2, 0xef, 0x3, 0x0, 0xaa, 0xed, 0x3, 0x1,
+				0xaa, 0xa9, 0x5, 0x40, 0xf9, 0xaa, 0x11, 0x40, 0xf9, 0xae, 0x1, 0x40, 0xf9, 0x4e, 0x0, 0x0, 0xb4, 0xc0, 0x1,
+				0x1f, 0xd6, 0xeb, 0x79, 0x69, 0x78, 0x6b, 0x5, 0x0, 0x11, 0xeb, 0x79, 0x29, 0x78, 0xa9, 0x5, 0x0, 0xf9, 0xaa,
+				0x11, 0x0, 0xf9, 0x8e, 0x0, 0x0, 0x10, 0xae, 0x1, 0x0, 0xf9, 0x60, 0x0, 0x80, 0xd2, 0xc0, 0x3, 0x5f, 0xd6,
+				0xa0, 0x9, 0x40, 0xf9, 0x1f, 0x4, 0x0, 0xf1, 0x81, 0x0, 0x0, 0x54, 0xe1, 0x5, 0x9, 0x8b, 0x2b, 0x0, 0x40,
+				0x39, 0x2b, 0x0, 0x0, 0x79, 0xeb, 0x79, 0x69, 0x78, 0xab, 0x0, 0x0, 0x34, 0x4a, 0x5, 0x0, 0xf1, 0xa0, 0x0,
+				0x0, 0x54, 0xeb, 0x79, 0x69, 0x78, 0xab, 0xff, 0xff, 0x35, 0x0, 0x0, 0x80, 0xd2, 0xc0, 0x3, 0x5f, 0xd6, 0xa9,
+				0x5, 0x0, 0xf9, 0xaa, 0x11, 0x0, 0xf9, 0x4e, 0xff, 0xff, 0x10, 0xae, 0x1, 0x0, 0xf9, 0x80, 0x0, 0x80, 0xd2,
+				0xc0, 0x3, 0x5f, 0xd6,
+			},
+		},
+		{
+			"32-bit",
+			32,
+			[]byte{
+				0x9, 0x0, 0x80, 0xd2, 0xa, 0x0, 0x80, 0xd2, 0xb, 0x0, 0x80, 0xd2, 0xef, 0x3, 0x0, 0xaa, 0xed, 0x3, 0x1,
+				0xaa, 0xa9, 0x5, 0x40, 0xf9, 0xaa, 0x11, 0x40, 0xf9, 0xae, 0x1, 0x40, 0xf9, 0x4e, 0x0, 0x0, 0xb4, 0xc0, 0x1,
+				0x1f, 0xd6, 0xeb, 0x79, 0x69, 0xb8, 0x6b, 0x41, 0x40, 0x11, 0x6b, 0x5, 0x0, 0x11, 0xeb, 0x79, 0x29, 0xb8, 0xa9,
+				0x5, 0x0, 0xf9, 0xaa, 0x11, 0x0, 0xf9, 0x8e, 0x0, 0x0, 0x10, 0xae, 0x1, 0x0, 0xf9, 0x60, 0x0, 0x80, 0xd2,
+				0xc0, 0x3, 0x5f, 0xd6, 0xa0, 0x9, 0x40, 0xf9, 0x1f, 0x4, 0x0, 0xf1, 0x81, 0x0, 0x0, 0x54, 0xe1, 0x9, 0x9,
+				0x8b, 0x2b, 0x0, 0x40, 0x39, 0x2b, 0x0, 0x0, 0xb9, 0xeb, 0x79, 0x69, 0xb8, 0xab, 0x0, 0x0, 0x34, 0x4a, 0x5,
+				0x0, 0xf1, 0xa0, 0x0, 0x0, 0x54, 0xeb, 0x79, 0x69, 0xb8, 0xab, 0xff, 0xff, 0x35, 0x0, 0x0, 0x80, 0xd2, 0xc0,
+				0x3, 0x5f, 0xd6, 0xa9, 0x5, 0x0, 0xf9, 0xaa, 0x11, 0x0, 0xf9, 0x4e, 0xff, 0xff, 0x10, 0xae, 0x1, 0x0, 0xf9,
+				0x80, 0x0, 0x80, 0xd2, 0xc0, 0x3, 0x5f, 0xd6,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jit := NewJit(execution.Options{MemorySize: 1000, CellSize: test.cellSize})
+			err := jit.compileAarch64(testInstructions)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, jit.code)
+		})
+	}
+}
+
+func TestJit_CompileAarch64EOF(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.Read, Value: 1},
+	}
+
+	jit := NewJit(execution.Options{MemorySize: 1000, EOF: execution.EOFMinusOne})
+	err := jit.compileAarch64(testInstructions)
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, []byte{
+		0x9, 0x0, 0x80, 0xd2, 0xa, 0x0, 0x80, 0xd2, 0xb, 0x0, 0x80, 0xd2, 0xef, 0x3, 0x0, 0xaa, 0xed, 0x3, 0x1,
+		0xaa, 0xa9, 0x5, 0x40, 0xf9, 0xaa, 0x11, 0x40, 0xf9, 0xae, 0x1, 0x40, 0xf9, 0x4e, 0x0, 0x0, 0xb4, 0xc0, 0x1,
+		0x1f, 0xd6, 0xa9, 0x5, 0x0, 0xf9, 0xaa, 0x11, 0x0, 0xf9, 0x8e, 0x0, 0x0, 0x10, 0xae, 0x1, 0x0, 0xf9, 0x60,
+		0x0, 0x80, 0xd2, 0xc0, 0x3, 0x5f, 0xd6, 0xa0, 0x9, 0x40, 0xf9, 0x1f, 0x4, 0x0, 0xf1, 0x60, 0x0, 0x0, 0x54,
+		0xeb, 0x1f, 0x80, 0xd2, 0xeb, 0x69, 0x29, 0x38, 0x0, 0x0, 0x80, 0xd2, 0xc0, 0x3, 0x5f, 0xd6,
+	}, jit.code)
+}
+
+func TestJit_CompileAarch64Unbuffered(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.Read, Value: 1},
+		{Name: instructions.Write, Value: 1},
+	}
+
+	jit := NewJit(execution.Options{MemorySize: 1000, Unbuffered: true})
+	err := jit.compileAarch64(testInstructions)
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, []byte{
+		0x9, 0x0, 0x80, 0xd2, 0xa, 0x0, 0x80, 0xd2, 0xb, 0x0, 0x80, 0xd2, 0xef, 0x3, 0x0, 0xaa, 0xed, 0x3, 0x1,
+		0xaa, 0xa9, 0x5, 0x40, 0xf9, 0xaa, 0x11, 0x40, 0xf9, 0xae, 0x1, 0x40, 0xf9, 0x4e, 0x0, 0x0, 0xb4, 0xc0, 0x1,
+		0x1f, 0xd6, 0xa9, 0x5, 0x0, 0xf9, 0xaa, 0x11, 0x0, 0xf9, 0x8e, 0x0, 0x0, 0x10, 0xae, 0x1, 0x0, 0xf9, 0x60,
+		0x0, 0x80, 0xd2, 0xc0, 0x3, 0x5f, 0xd6, 0xa0, 0x9, 0x40, 0xf9, 0xeb, 0x69, 0x69, 0x38, 0xae, 0xd, 0x40, 0xf9,
+		0xa1, 0x1, 0xe, 0x8b, 0x2b, 0xa0, 0x0, 0x39, 0xce, 0x5, 0x0, 0x91, 0xae, 0xd, 0x0, 0xf9, 0xa9, 0x5, 0x0,
+		0xf9, 0xaa, 0x11, 0x0, 0xf9, 0x8e, 0x0, 0x0, 0x10, 0xae, 0x1, 0x0, 0xf9, 0x40, 0x0, 0x80, 0xd2, 0xc0, 0x3,
+		0x5f, 0xd6, 0x0, 0x0, 0x80, 0xd2, 0xc0, 0x3, 0x5f, 0xd6,
+	}, jit.code)
+}
+
+func TestJit_DisassembleAarch64(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.JumpIfZero, Value: 2, Span: instructions.NewSpan(instructions.Position{Line: 1, Column: 1})},
+		{Name: instructions.Decrement, Value: 1, Offset: 1},
+		{Name: instructions.JumpUnlessZero, Value: 0},
+	}
+
+	jit := NewJit(execution.Options{MemorySize: 1000, BoundsCheck: true})
+	assert.NoError(t, jit.compileAarch64(testInstructions))
+
+	assert.Equal(t, `prologue:
+  0000  09 00 80 d2  mov     x9, #0
+  0004  0a 00 80 d2  mov     x10, #0
+  0008  0b 00 80 d2  mov     x11, #0
+  000c  ef 03 00 aa  mov     x15, x0
+  0010  ed 03 01 aa  mov     x13, x1
+  0014  0c 7d 80 d2  mov     x12, #1000
+  0018  a9 05 40 f9  ldr     x9, [x13, #8]
+  001c  aa 11 40 f9  ldr     x10, [x13, #32]
+  0020  ae 01 40 f9  ldr     x14, [x13]
+  0024  4e 00 00 b4  cbz     x14, 0x2c
+  0028  c0 01 1f d6  br      x14
+0 JumpIfZero -> 2 (1:1):
+  002c  eb 69 69 38  ldrb    w11, [x15, x9]
+  0030  6b 01 00 34  cbz     w11, 0x5c
+1 Decrement 1 [+1]:
+  0034  2e 05 00 91  add     x14, x9, #1
+  0038  df 01 0c eb  cmp     x14, x12
+  003c  42 01 00 54  b.hs    0x64
+  0040  eb 69 6e 38  ldrb    w11, [x15, x14]
+  0044  6b 05 00 51  sub     w11, w11, #1
+  0048  eb 69 2e 38  strb    w11, [x15, x14]
+2 JumpUnlessZero -> 0:
+  004c  4a 05 00 f1  subs    x10, x10, #1
+  0050  40 01 00 54  b.eq    0x78
+  0054  eb 69 69 38  ldrb    w11, [x15, x9]
+  0058  eb fe ff 35  cbnz    w11, 0x34
+epilogue:
+  005c  00 00 80 d2  mov     x0, #0
+  0060  c0 03 5f d6  ret
+out of bounds stub for 1 Decrement 1 [+1]:
+  0064  20 00 80 d2  mov     x0, #1
+  0068  21 00 80 d2  mov     x1, #1
+  006c  e2 03 09 aa  mov     x2, x9
+  0070  42 04 00 91  add     x2, x2, #1
+  0074  c0 03 5f d6  ret
+yield stub for 2 JumpUnlessZero -> 0:
+  0078  a9 05 00 f9  str     x9, [x13, #8]
+  007c  aa 11 00 f9  str     x10, [x13, #32]
+  0080  ae fe ff 10  adr     x14, 0x54
+  0084  ae 01 00 f9  str     x14, [x13]
+  0088  80 00 80 d2  mov     x0, #4
+  008c  c0 03 5f d6  ret
+`, jit.Disassemble())
+}
+
+func TestJit_DecodeAarch64(t *testing.T) {
+	var tests = []struct {
+		instruction uint32
+		expected    string
+	}{
+		{0x91400529, "add     x9, x9, #1, lsl #12"},
+		{0xd2a00020, "mov     x0, #65536"},
+		{0xb86e59e1, "ldr     w1, [x15, w14, uxtw #2]"},
+		{0x1b031041, "madd    w1, w2, w3, w4"},
+		{0x17ffffff, "b       0xfc"},
+		{0x00000000, "(bad)"},
+	}
+
+	for _, test := range tests {
+		code := binary.LittleEndian.AppendUint32(nil, test.instruction)
+
+		size, text := decodeAarch64(code, 0x100)
+		assert.Equal(t, 4, size)
+		assert.Equal(t, test.expected, text)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build (darwin || linux) && arm64
+
+package jit
+
+import "gobf/instructions"
+
+func (jit *Jit) Compile(parsedInstructions []instructions.Instruction) error {
+	return jit.compileAarch64(parsedInstructions)
+}
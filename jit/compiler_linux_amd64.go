@@ -0,0 +1,599 @@
+//go:build linux && amd64
+
+package jit
+
+import (
+	"encoding/binary"
+	"errors"
+	"gobf/execution"
+	"gobf/instructions"
+)
+
+const (
+	OpcodeJe  = uint16(0x840f)
+	OpcodeJne = uint16(0x850f)
+	OpcodeJae = uint16(0x830f)
+)
+
+func (jit *Jit) Compile(parsedInstructions []instructions.Instruction) error {
+	// rax contains a pointer to program memory
+	// rbx contains a pointer to the state shared with Go
+
+	// r8 = pointer to the state
+	// r9 = step counter, returns to Go when it reaches 0
+	// r12 = pointer to program memory
+	// r13 = address counter, in cells
+
+	// memory operands address the current cell using [r12+r13*cellBytes]
+
+	// on return rax contains the exit code, rbx the instruction index and rcx the address counter
+
+	if err := jit.checkOptions(); err != nil {
+		return err
+	}
+
+	var exitStubs []exitStub
+	var yieldStubs []yieldStub
+
+	jit.decode = decodeAmd64
+
+	jit.code = append(jit.code,
+		// move first argument(pointer to program memory) to r12
+		0x49, 0x89, 0xc4, // mov r12, rax
+
+		// move second argument(pointer to the state) to r8
+		0x49, 0x89, 0xd8, // mov r8, rbx
+
+		// restore the address counter, which is 0 when the program starts
+		0x4d, 0x8b, 0x68, 0x08, // mov r13, [r8+8]
+
+		// restore the step counter
+		0x4d, 0x8b, 0x48, 0x20, // mov r9, [r8+32]
+
+		// continue where we returned to Go, unless the program starts
+		0x49, 0x8b, 0x00, // mov rax, [r8]
+		0x48, 0x85, 0xc0, // test rax, rax
+		0x74, 0x02, // jz start
+		0xff, 0xe0, // jmp rax
+	)
+
+	for index, instruction := range parsedInstructions {
+		block := CodeBlock{
+			instruction: instruction,
+			offset:      len(jit.code),
+		}
+
+		// the cell at the offset of the instruction is addressed using a 32-bit displacement
+		displacement := instruction.Offset * jit.options.CellBytes()
+		if displacement < -(1<<31) || displacement >= 1<<31 {
+			return errors.New("offset out of range")
+		}
+
+		// instructions working on the cell at an offset check it before accessing it, Read moves the address counter
+		if jit.options.BoundsCheck && instruction.Offset != 0 && instruction.Name != instructions.Read {
+			exitStubs = append(exitStubs, jit.appendBoundsCheck(index, instruction.Offset))
+		}
+
+		switch instruction.Name {
+		case instructions.MoveRight:
+			// increase the address counter by instruction value
+			if err := jit.encodeAndAppendAddressInstruction(0xc5, instruction.Value); err != nil {
+				return err
+			}
+
+			if jit.options.BoundsCheck {
+				exitStubs = append(exitStubs, jit.appendBoundsCheck(index, 0))
+			}
+		case instructions.MoveLeft:
+			// decrease the address counter by instruction value
+			if err := jit.encodeAndAppendAddressInstruction(0xed, instruction.Value); err != nil {
+				return err
+			}
+
+			if jit.options.BoundsCheck {
+				exitStubs = append(exitStubs, jit.appendBoundsCheck(index, 0))
+			}
+		case instructions.Increment:
+			// add instruction value to the program memory offset by the address counter
+			jit.appendCellImmediateInstruction(0x80, 0x81, 0, instruction.Offset, instruction.Value) // add [r12+r13], imm
+		case instructions.Decrement:
+			// subtract instruction value from the program memory offset by the address counter
+			jit.appendCellImmediateInstruction(0x80, 0x81, 5, instruction.Offset, instruction.Value) // sub [r12+r13], imm
+		case instructions.Write:
+			jit.appendBufferedWrite(instruction.Offset)
+		case instructions.Read:
+			// Go reads into the current cell, so move the address counter to the cell at the offset until it's done
+			if err := jit.appendAddressOffset(instruction.Offset); err != nil {
+				return err
+			}
+
+			if jit.options.BoundsCheck && instruction.Offset != 0 {
+				exitStubs = append(exitStubs, jit.appendBoundsCheck(index, 0))
+			}
+
+			// let Go read a byte into the current cell
+			jit.appendReturnToGo(exitCodeRead)
+
+			// load the number of bytes read
+			jit.code = append(jit.code, 0x41, 0x8b, 0x40, 0x10) // mov eax, [r8+16]
+
+			if jit.options.EOF != execution.EOFUnchanged {
+				jit.appendReadEOF()
+			}
+
+			if jit.options.CellBytes() > 1 {
+				jit.appendZeroExtendReadByte()
+			}
+
+			if err := jit.appendAddressOffset(-instruction.Offset); err != nil {
+				return err
+			}
+		case instructions.JumpIfZero:
+			// compare the current value of the program memory offset by the address counter with 0
+			jit.appendCellImmediateInstruction(0x80, 0x81, 7, 0, 0) // cmp [r12+r13], 0
+
+			// jump to right after the linked jump instruction
+			jit.code = append(jit.code, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0) // placeholder
+		case instructions.JumpUnlessZero:
+			// count the step, and let Go check whether the program may continue once the counter reaches 0
+			jit.code = append(jit.code, 0x49, 0xff, 0xc9)             // dec r9
+			jit.code = append(jit.code, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0) // placeholder for jz rel32
+
+			// kept out of the loop, so it only costs a jump which is almost never taken
+			yieldStubs = append(yieldStubs, yieldStub{offset: len(jit.code) - 6, instruction: index, resume: len(jit.code)})
+
+			// compare the current value of the program memory offset by the address counter with 0
+			jit.appendCellImmediateInstruction(0x80, 0x81, 7, 0, 0) // cmp [r12+r13], 0
+
+			// jump to right after the linked jump instruction
+			jit.code = append(jit.code, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0) // placeholder
+		case instructions.Clear:
+			// store a zero value in the program memory offset by the address counter
+			jit.appendCellImmediateInstruction(0xc6, 0xc7, 0, instruction.Offset, 0) // mov [r12+r13], 0
+		case instructions.MulAdd:
+			jit.appendMulAdd(instruction.Offset, instruction.Value)
+		case instructions.Set:
+			// store the instruction value in the program memory offset by the address counter
+			jit.appendCellImmediateInstruction(0xc6, 0xc7, 0, instruction.Offset, instruction.Value) // mov [r12+r13], imm
+		case instructions.WriteString:
+			// let Go write the text of this instruction
+			jit.code = append(jit.code, 0xbb) // mov ebx, imm32
+			jit.code = binary.LittleEndian.AppendUint32(jit.code, uint32(index))
+
+			jit.appendReturnToGo(exitCodeWriteString)
+		case instructions.ScanRight, instructions.ScanLeft:
+			scanRight := instruction.Name == instructions.ScanRight
+
+			if jit.options.CellBytes() == 1 && instruction.Value == 1 && !jit.options.BoundsCheck && !jit.options.SimpleCodegen {
+				jit.appendVectorScan(scanRight)
+				break
+			}
+
+			modrm := byte(0xc5) // add r13, imm32
+			if !scanRight {
+				modrm = 0xed // sub r13, imm32
+			}
+
+			// check the current cell first, the pointer only moves while it isn't zero
+			jit.code = append(jit.code, 0xeb, 0x0) // jmp check
+			loop := len(jit.code)
+
+			if err := jit.encodeAndAppendAddressInstruction(modrm, instruction.Value); err != nil {
+				return err
+			}
+
+			if jit.options.BoundsCheck {
+				exitStubs = append(exitStubs, jit.appendBoundsCheck(index, 0))
+			}
+
+			jit.code[loop-1] = byte(len(jit.code) - loop)
+
+			jit.appendCellImmediateInstruction(0x80, 0x81, 7, 0, 0)       // cmp [r12+r13], 0
+			jit.code = append(jit.code, 0x75, byte(loop-len(jit.code)-2)) // jne loop
+		}
+
+		block.end = len(jit.code)
+		jit.codeBlocks = append(jit.codeBlocks, block)
+	}
+
+	jit.epilogue = len(jit.code)
+
+	jit.code = append(jit.code,
+		// return back to our Go program with a successful exit code
+		0x31, 0xc0, // xor eax, eax
+		0xc3, // ret
+	)
+
+	for _, stub := range exitStubs {
+		start := len(jit.code)
+
+		// jump displacements are relative to the end of the 6 byte jump instruction
+		encodeJumpInstruction(jit.code[stub.offset:], OpcodeJae, len(jit.code)-stub.offset-6)
+
+		jit.code = append(jit.code, 0xb8) // mov eax, imm32
+		jit.code = binary.LittleEndian.AppendUint32(jit.code, exitCodeOutOfBounds)
+
+		jit.code = append(jit.code, 0xbb) // mov ebx, imm32
+		jit.code = binary.LittleEndian.AppendUint32(jit.code, uint32(stub.instruction))
+
+		if stub.pointerOffset == 0 {
+			jit.code = append(jit.code, 0x4c, 0x89, 0xe9) // mov rcx, r13
+		} else {
+			jit.code = append(jit.code, 0x49, 0x8d, 0x8d) // lea rcx, [r13+imm32]
+			jit.code = binary.LittleEndian.AppendUint32(jit.code, uint32(int32(stub.pointerOffset)))
+		}
+
+		jit.code = append(jit.code, 0xc3) // ret
+
+		jit.stubs = append(jit.stubs, stubCode{"out of bounds", stub.instruction, start, len(jit.code)})
+	}
+
+	for _, stub := range yieldStubs {
+		start := len(jit.code)
+
+		encodeJumpInstruction(jit.code[stub.offset:], OpcodeJe, len(jit.code)-stub.offset-6)
+
+		jit.appendReturnToGoResumingAt(exitCodeYield, stub.resume)
+
+		jit.stubs = append(jit.stubs, stubCode{"yield", stub.instruction, start, len(jit.code)})
+	}
+
+	if err := jit.postProcessJumps(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// appendBoundsCheck compares the address counter plus pointerOffset with the memory size, and jumps to an exit stub
+// when it is out of range
+func (jit *Jit) appendBoundsCheck(index int, pointerOffset int) exitStub {
+	// unsigned comparison, so a negative address counter is also out of range
+	if pointerOffset == 0 {
+		jit.code = append(jit.code, 0x49, 0x81, 0xfd) // cmp r13, imm32
+	} else {
+		jit.code = append(jit.code, 0x49, 0x8d, 0x85) // lea rax, [r13+imm32]
+		jit.code = binary.LittleEndian.AppendUint32(jit.code, uint32(int32(pointerOffset)))
+
+		jit.code = append(jit.code, 0x48, 0x3d) // cmp rax, imm32
+	}
+
+	jit.code = binary.LittleEndian.AppendUint32(jit.code, uint32(jit.options.MemorySize))
+
+	stub := exitStub{
+		offset:        len(jit.code),
+		instruction:   index,
+		pointerOffset: pointerOffset,
+	}
+
+	jit.code = append(jit.code, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0) // placeholder for jae rel32
+
+	return stub
+}
+
+func (jit *Jit) postProcessJumps() error {
+	for i, block := range jit.codeBlocks {
+		if !block.instruction.IsJump() {
+			continue
+		}
+
+		jit.codeBlocks[i].link = &jit.codeBlocks[block.instruction.Value]
+	}
+
+	for _, block := range jit.codeBlocks {
+		// Only process jump instructions
+		if !block.instruction.IsJump() {
+			continue
+		}
+
+		// Only process linked blocks
+		if block.link == nil {
+			return errors.New("failed to link code block")
+		}
+
+		opcode := OpcodeJe
+		if block.instruction.Name == instructions.JumpUnlessZero {
+			opcode = OpcodeJne
+		}
+
+		// Jump blocks end with their jump, so the distance from the end of our jump to the end of the linked jump is
+		// the distance between the ends of both blocks
+		offset := block.link.end - block.end
+
+		// the jump is the last 6 bytes of the block
+		encodeJumpInstruction(jit.code[block.end-6:], opcode, offset)
+	}
+
+	return nil
+}
+
+func (jit *Jit) encodeAndAppendAddressInstruction(modrm byte, immediate int) error {
+	if immediate < 0 || immediate >= 1<<31 {
+		return errors.New("immediate out of range")
+	}
+
+	// REX.W + B prefix, 0x81 opcode group with r13 as the target register encoded in the ModRM byte
+	jit.code = append(jit.code, 0x49, 0x81, modrm)
+	jit.code = binary.LittleEndian.AppendUint32(jit.code, uint32(immediate))
+
+	return nil
+}
+
+// appendAddressOffset adds a positive or negative offset to the address counter
+func (jit *Jit) appendAddressOffset(offset int) error {
+	if offset < 0 {
+		return jit.encodeAndAppendAddressInstruction(0xed, -offset) // sub r13, imm32
+	}
+
+	if offset > 0 {
+		return jit.encodeAndAppendAddressInstruction(0xc5, offset) // add r13, imm32
+	}
+
+	return nil
+}
+
+// appendCellOperand appends the ModRM and SIB bytes addressing the cell at offset from the current cell,
+// [r12+r13*cellBytes+displacement], with reg as register operand or opcode extension. The displacement is left out
+// for the current cell, and encoded in a single byte when it fits
+func (jit *Jit) appendCellOperand(reg byte, offset int) {
+	displacement := offset * jit.options.CellBytes()
+
+	switch {
+	case displacement == 0:
+		jit.code = append(jit.code, reg<<3|0x04, jit.cellScaleIndexBase())
+	case displacement >= -128 && displacement < 128:
+		jit.code = append(jit.code, reg<<3|0x44, jit.cellScaleIndexBase(), byte(int8(displacement)))
+	default:
+		jit.code = append(jit.code, reg<<3|0x84, jit.cellScaleIndexBase())
+		jit.code = binary.LittleEndian.AppendUint32(jit.code, uint32(int32(displacement)))
+	}
+}
+
+// appendCellImmediateInstruction appends an instruction operating on the cell at offset from the current cell with an
+// immediate operand, using the 8-bit opcode or the 16/32-bit opcode depending on the cell size. The immediate is
+// truncated to the cell size
+func (jit *Jit) appendCellImmediateInstruction(opcode8 byte, opcode byte, reg byte, offset int, immediate int) {
+	cellBytes := jit.options.CellBytes()
+
+	// operand size prefix, switching the 32-bit opcode to 16 bits
+	if cellBytes == 2 {
+		jit.code = append(jit.code, 0x66)
+	}
+
+	if cellBytes == 1 {
+		opcode = opcode8
+	}
+
+	// REX.X + B prefix for r13 as index and r12 as base
+	jit.code = append(jit.code, 0x43, opcode)
+	jit.appendCellOperand(reg, offset)
+
+	for i := 0; i < cellBytes; i++ {
+		jit.code = append(jit.code, byte(immediate>>(i*8)))
+	}
+}
+
+// appendMulAdd adds the current cell multiplied by factor to the cell at offset from it
+func (jit *Jit) appendMulAdd(offset int, factor int) {
+	cellBytes := jit.options.CellBytes()
+
+	// load the current cell, zero extending it
+	switch cellBytes {
+	case 2:
+		jit.code = append(jit.code, 0x43, 0x0f, 0xb7, 0x04, jit.cellScaleIndexBase()) // movzx eax, word [r12+r13*2]
+	case 4:
+		jit.code = append(jit.code, 0x43, 0x8b, 0x04, jit.cellScaleIndexBase()) // mov eax, [r12+r13*4]
+	default:
+		jit.code = append(jit.code, 0x43, 0x0f, 0xb6, 0x04, jit.cellScaleIndexBase()) // movzx eax, byte [r12+r13]
+	}
+
+	// copies don't need a multiplication, the upper bits of the product are truncated by the add
+	if uint32(factor)&jit.options.CellMask() != 1 {
+		jit.code = append(jit.code, 0x69, 0xc0) // imul eax, eax, imm32
+		jit.code = binary.LittleEndian.AppendUint32(jit.code, uint32(factor))
+	}
+
+	// operand size prefix, switching the 32-bit opcode to 16 bits
+	if cellBytes == 2 {
+		jit.code = append(jit.code, 0x66)
+	}
+
+	opcode := byte(0x01)
+	if cellBytes == 1 {
+		opcode = 0x00
+	}
+
+	// al, ax or eax as the source
+	jit.code = append(jit.code, 0x43, opcode) // add [r12+r13+displacement], eax
+	jit.appendCellOperand(0, offset)
+}
+
+// appendVectorScan moves the address counter to the nearest zero byte at or after it, or at or before it when scanning
+// left, comparing 16 bytes at a time using SSE2. Only used for byte cells with a stride of 1 without bounds checking.
+// Loads are aligned to 16 bytes so they never cross a page, only the load reaching a guard page faults
+func (jit *Jit) appendVectorScan(scanRight bool) {
+	compare := []byte{
+		0x66, 0x0f, 0x6f, 0x08, // movdqa xmm1, [rax]
+		0x66, 0x0f, 0x74, 0xc8, // pcmpeqb xmm1, xmm0
+		0x66, 0x0f, 0xd7, 0xd1, // pmovmskb edx, xmm1
+	}
+
+	jit.code = append(jit.code,
+		// align the address of the current cell down to 16 bytes, and keep the position of the cell within them in ecx
+		0x4b, 0x8d, 0x04, 0x2c, // lea rax, [r12+r13]
+		0x89, 0xc1, // mov ecx, eax
+		0x83, 0xe1, 0x0f, // and ecx, 15
+		0x48, 0x83, 0xe0, 0xf0, // and rax, -16
+
+		0x66, 0x0f, 0xef, 0xc0, // pxor xmm0, xmm0
+	)
+
+	// edx gets a bit set for every zero byte
+	jit.code = append(jit.code, compare...)
+
+	if scanRight {
+		// ignore zero bytes before the current cell
+		jit.code = append(jit.code,
+			0xbe, 0xff, 0xff, 0xff, 0xff, // mov esi, -1
+			0xd3, 0xe6, // shl esi, cl
+		)
+	} else {
+		// ignore zero bytes after the current cell
+		jit.code = append(jit.code,
+			0xbe, 0x02, 0x00, 0x00, 0x00, // mov esi, 2
+			0xd3, 0xe6, // shl esi, cl
+			0xff, 0xce, // dec esi
+		)
+	}
+
+	jit.code = append(jit.code,
+		0x21, 0xf2, // and edx, esi
+		0x85, 0xd2, // test edx, edx
+	)
+
+	jit.appendSkip(0x75, func() { // jnz found
+		loop := len(jit.code)
+
+		if scanRight {
+			jit.code = append(jit.code, 0x48, 0x83, 0xc0, 0x10) // add rax, 16
+		} else {
+			jit.code = append(jit.code, 0x48, 0x83, 0xe8, 0x10) // sub rax, 16
+		}
+
+		jit.code = append(jit.code, compare...)
+		jit.code = append(jit.code, 0x85, 0xd2)                       // test edx, edx
+		jit.code = append(jit.code, 0x74, byte(loop-len(jit.code)-2)) // jz loop
+	})
+
+	// the lowest bit is the nearest zero byte to the right, the highest bit the nearest to the left
+	if scanRight {
+		jit.code = append(jit.code, 0x0f, 0xbc, 0xd2) // bsf edx, edx
+	} else {
+		jit.code = append(jit.code, 0x0f, 0xbd, 0xd2) // bsr edx, edx
+	}
+
+	jit.code = append(jit.code,
+		0x48, 0x01, 0xd0, // add rax, rdx
+		0x4c, 0x29, 0xe0, // sub rax, r12
+		0x49, 0x89, 0xc5, // mov r13, rax
+	)
+}
+
+// cellScaleIndexBase returns the SIB byte for [r12+r13*cellBytes]
+func (jit *Jit) cellScaleIndexBase() byte {
+	switch jit.options.CellBytes() {
+	case 2:
+		return 0x6c
+	case 4:
+		return 0xac
+	}
+
+	return 0x2c
+}
+
+// appendZeroExtendReadByte clears the upper bits of a cell after a byte has been read into its lowest byte,
+// the cell is left unchanged when nothing was read
+func (jit *Jit) appendZeroExtendReadByte() {
+	store := []byte{0x43, 0x89, 0x04, jit.cellScaleIndexBase()} // mov [r12+r13], eax
+	if jit.options.CellBytes() == 2 {
+		store = append([]byte{0x66}, store...) // mov [r12+r13], ax
+	}
+
+	jit.code = append(jit.code,
+		// skip when Go didn't read exactly 1 byte
+		0x83, 0xf8, 0x01, // cmp eax, 1
+		0x75, byte(5+len(store)), // jne skip
+
+		// load the byte we've read and zero extend it
+		0x43, 0x0f, 0xb6, 0x04, jit.cellScaleIndexBase(), // movzx eax, byte [r12+r13]
+	)
+
+	jit.code = append(jit.code, store...)
+}
+
+// appendBufferedWrite appends the lowest byte of the cell at offset from the current cell to the output buffer, and
+// lets Go flush the buffer once it is full, or right away when output is unbuffered
+func (jit *Jit) appendBufferedWrite(offset int) {
+	jit.code = append(jit.code,
+		// load the number of buffered bytes
+		0x49, 0x8b, 0x40, 0x18, // mov rax, [r8+24]
+
+		// load the lowest byte of the cell
+		0x43, 0x0f, 0xb6, // movzx edx, byte [r12+r13]
+	)
+
+	jit.appendCellOperand(2, offset)
+
+	jit.code = append(jit.code,
+		// store it after the buffered bytes
+		0x41, 0x88, 0x54, 0x00, 0x28, // mov [r8+rax+40], dl
+
+		// store the new number of buffered bytes
+		0x48, 0xff, 0xc0, // inc rax
+		0x49, 0x89, 0x40, 0x18, // mov [r8+24], rax
+	)
+
+	if jit.options.Unbuffered {
+		jit.appendReturnToGo(exitCodeFlush)
+
+		return
+	}
+
+	jit.code = append(jit.code, 0x48, 0x3d) // cmp rax, imm32
+	jit.code = binary.LittleEndian.AppendUint32(jit.code, outputBufferSize)
+
+	jit.appendSkip(0x72, func() { // jb skip
+		jit.appendReturnToGo(exitCodeFlush)
+	})
+}
+
+// appendReturnToGo saves the address counter, the step counter and the address to resume at, and returns to Go with
+// the exit code. The program continues right after it once Go calls the generated code again
+func (jit *Jit) appendReturnToGo(exitCode uint32) {
+	// the resume address is right after the ret, 24 bytes further
+	jit.appendReturnToGoResumingAt(exitCode, len(jit.code)+24)
+}
+
+// appendReturnToGoResumingAt is appendReturnToGo continuing at the resume offset in the generated code
+func (jit *Jit) appendReturnToGoResumingAt(exitCode uint32, resume int) {
+	jit.code = append(jit.code,
+		0x4d, 0x89, 0x68, 0x08, // mov [r8+8], r13
+		0x4d, 0x89, 0x48, 0x20, // mov [r8+32], r9
+	)
+
+	// rip-relative addresses are relative to the end of the 7 byte lea instruction
+	jit.code = append(jit.code, 0x48, 0x8d, 0x05) // lea rax, [rip+rel32]
+	jit.code = binary.LittleEndian.AppendUint32(jit.code, uint32(int32(resume-len(jit.code)-4)))
+
+	jit.code = append(jit.code, 0x49, 0x89, 0x00) // mov [r8], rax
+
+	jit.code = append(jit.code, 0xb8) // mov eax, imm32
+	jit.code = binary.LittleEndian.AppendUint32(jit.code, exitCode)
+
+	jit.code = append(jit.code, 0xc3) // ret
+}
+
+// appendSkip appends a short conditional jump with the given opcode, which skips the code appended by body
+func (jit *Jit) appendSkip(opcode byte, body func()) {
+	jit.code = append(jit.code, opcode, 0x0) // placeholder for rel8
+
+	start := len(jit.code)
+	body()
+
+	jit.code[start-1] = byte(len(jit.code) - start)
+}
+
+// appendReadEOF stores the configured EOF value in the current cell when Go didn't read exactly 1 byte
+func (jit *Jit) appendReadEOF() {
+	jit.code = append(jit.code, 0x83, 0xf8, 0x01) // cmp eax, 1
+
+	jit.appendSkip(0x74, func() { // je skip
+		jit.appendCellImmediateInstruction(0xc6, 0xc7, 0, 0, int(jit.options.EOFValue())) // mov [r12+r13], imm
+	})
+}
+
+func encodeJumpInstruction(code []byte, opcode uint16, offset int) {
+	// Two byte opcode followed by a signed 32-bit displacement
+	binary.LittleEndian.PutUint16(code, opcode)
+	binary.LittleEndian.PutUint32(code[2:], uint32(int32(offset)))
+}
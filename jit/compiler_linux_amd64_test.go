@@ -0,0 +1,405 @@
+package jit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gobf/execution"
+	"gobf/instructions"
+	"testing"
+)
+
+func TestJit_Compile(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.MoveRight},
+		{Name: instructions.MoveLeft},
+		{Name: instructions.Increment},
+		{Name: instructions.JumpIfZero},
+		{Name: instructions.Increment},
+		{Name: instructions.Decrement},
+		{Name: instructions.Increment},
+		{Name: instructions.JumpUnlessZero},
+		{Name: instructions.Read},
+		{Name: instructions.Write},
+		{Name: instructions.Clear},
+	}
+
+	jit := NewJit(execution.Options{MemorySize: 1000})
+	err := jit.Compile(testInstructions)
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, []byte{
+		0x49, 0x89, 0xc4, 0x49, 0x89, 0xd8, 0x4d, 0x8b, 0x68, 0x8, 0x4d, 0x8b, 0x48, 0x20, 0x49, 0x8b, 0x0, 0x48, 0x85,
+		0xc0, 0x74, 0x2, 0xff, 0xe0, 0x49, 0x81, 0xc5, 0x0, 0x0, 0x0, 0x0, 0x49, 0x81, 0xed, 0x0, 0x0, 0x0, 0x0,
+		0x43, 0x80, 0x4, 0x2c, 0x0, 0x43, 0x80, 0x3c, 0x2c, 0x0, 0xf, 0x84, 0xe9, 0xff, 0xff, 0xff, 0x43, 0x80, 0x4,
+		0x2c, 0x0, 0x43, 0x80, 0x2c, 0x2c, 0x0, 0x43, 0x80, 0x4, 0x2c, 0x0, 0x49, 0xff, 0xc9, 0xf, 0x84, 0x64, 0x0,
+		0x0, 0x0, 0x43, 0x80, 0x3c, 0x2c, 0x0, 0xf, 0x85, 0xc6, 0xff, 0xff, 0xff, 0x4d, 0x89, 0x68, 0x8, 0x4d, 0x89,
+		0x48, 0x20, 0x48, 0x8d, 0x5, 0x9, 0x0, 0x0, 0x0, 0x49, 0x89, 0x0, 0xb8, 0x3, 0x0, 0x0, 0x0, 0xc3, 0x41,
+		0x8b, 0x40, 0x10, 0x49, 0x8b, 0x40, 0x18, 0x43, 0xf, 0xb6, 0x14, 0x2c, 0x41, 0x88, 0x54, 0x0, 0x28, 0x48, 0xff,
+		0xc0, 0x49, 0x89, 0x40, 0x18, 0x48, 0x3d, 0x0, 0x10, 0x0, 0x0, 0x72, 0x18, 0x4d, 0x89, 0x68, 0x8, 0x4d, 0x89,
+		0x48, 0x20, 0x48, 0x8d, 0x5, 0x9, 0x0, 0x0, 0x0, 0x49, 0x89, 0x0, 0xb8, 0x2, 0x0, 0x0, 0x0, 0xc3, 0x43,
+		0xc6, 0x4, 0x2c, 0x0, 0x31, 0xc0, 0xc3, 0x4d, 0x89, 0x68, 0x8, 0x4d, 0x89, 0x48, 0x20, 0x48, 0x8d, 0x5, 0x8d,
+		0xff, 0xff, 0xff, 0x49, 0x89, 0x0, 0xb8, 0x4, 0x0, 0x0, 0x0, 0xc3,
+	}, jit.code)
+}
+
+func TestJit_CompileBoundsCheck(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.MoveLeft, Value: 1},
+		{Name: instructions.MoveRight, Value: 1},
+	}
+
+	jit := NewJit(execution.Options{MemorySize: 1000, BoundsCheck: true})
+	err := jit.Compile(testInstructions)
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, []byte{
+		0x49, 0x89, 0xc4, 0x49, 0x89, 0xd8, 0x4d, 0x8b, 0x68, 0x8, 0x4d, 0x8b, 0x48, 0x20, 0x49, 0x8b, 0x0, 0x48, 0x85,
+		0xc0, 0x74, 0x2, 0xff, 0xe0, 0x49, 0x81, 0xed, 0x1, 0x0, 0x0, 0x0, 0x49, 0x81, 0xfd, 0xe8, 0x3, 0x0, 0x0,
+		0xf, 0x83, 0x17, 0x0, 0x0, 0x0, 0x49, 0x81, 0xc5, 0x1, 0x0, 0x0, 0x0, 0x49, 0x81, 0xfd, 0xe8, 0x3, 0x0,
+		0x0, 0xf, 0x83, 0x11, 0x0, 0x0, 0x0, 0x31, 0xc0, 0xc3, 0xb8, 0x1, 0x0, 0x0, 0x0, 0xbb, 0x0, 0x0, 0x0,
+		0x0, 0x4c, 0x89, 0xe9, 0xc3, 0xb8, 0x1, 0x0, 0x0, 0x0, 0xbb, 0x1, 0x0, 0x0, 0x0, 0x4c, 0x89, 0xe9, 0xc3,
+	}, jit.code)
+}
+
+func TestJit_CompileMulAdd(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.MulAdd, Value: -2, Offset: -1},
+		{Name: instructions.MulAdd, Value: 1, Offset: 2},
+	}
+
+	jit := NewJit(execution.Options{MemorySize: 1000, CellSize: 16, BoundsCheck: true})
+	err := jit.Compile(testInstructions)
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, []byte{
+		0x49, 0x89, 0xc4, 0x49, 0x89, 0xd8, 0x4d, 0x8b, 0x68, 0x8, 0x4d, 0x8b, 0x48, 0x20, 0x49, 0x8b, 0x0, 0x48, 0x85,
+		0xc0, 0x74, 0x2, 0xff, 0xe0, 0x49, 0x8d, 0x85, 0xff, 0xff, 0xff, 0xff, 0x48, 0x3d, 0xe8, 0x3, 0x0, 0x0, 0xf,
+		0x83, 0x32, 0x0, 0x0, 0x0, 0x43, 0xf, 0xb7, 0x4, 0x6c, 0x69, 0xc0, 0xfe, 0xff, 0xff, 0xff, 0x66, 0x43, 0x1,
+		0x44, 0x6c, 0xfe, 0x49, 0x8d, 0x85, 0x2, 0x0, 0x0, 0x0, 0x48, 0x3d, 0xe8, 0x3, 0x0, 0x0, 0xf, 0x83, 0x20,
+		0x0, 0x0, 0x0, 0x43, 0xf, 0xb7, 0x4, 0x6c, 0x66, 0x43, 0x1, 0x44, 0x6c, 0x4, 0x31, 0xc0, 0xc3, 0xb8, 0x1,
+		0x0, 0x0, 0x0, 0xbb, 0x0, 0x0, 0x0, 0x0, 0x49, 0x8d, 0x8d, 0xff, 0xff, 0xff, 0xff, 0xc3, 0xb8, 0x1, 0x0,
+		0x0, 0x0, 0xbb, 0x1, 0x0, 0x0, 0x0, 0x49, 0x8d, 0x8d, 0x2, 0x0, 0x0, 0x0, 0xc3,
+	}, jit.code)
+}
+
+func TestJit_CompileOffsets(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.Increment, Value: 1, Offset: 1},
+		{Name: instructions.Decrement, Value: 2, Offset: -100},
+		{Name: instructions.Write, Value: 1, Offset: 2},
+		{Name: instructions.Read, Value: 1, Offset: -1},
+		{Name: instructions.Clear, Offset: 3},
+	}
+
+	jit := NewJit(execution.Options{MemorySize: 1000, CellSize: 16, BoundsCheck: true})
+	err := jit.Compile(testInstructions)
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, []byte{
+		0x49, 0x89, 0xc4, 0x49, 0x89, 0xd8, 0x4d, 0x8b, 0x68, 0x8, 0x4d, 0x8b, 0x48, 0x20, 0x49, 0x8b, 0x0, 0x48, 0x85,
+		0xc0, 0x74, 0x2, 0xff, 0xe0, 0x49, 0x8d, 0x85, 0x1, 0x0, 0x0, 0x0, 0x48, 0x3d, 0xe8, 0x3, 0x0, 0x0, 0xf,
+		0x83, 0xd3, 0x0, 0x0, 0x0, 0x66, 0x43, 0x81, 0x44, 0x6c, 0x2, 0x1, 0x0, 0x49, 0x8d, 0x85, 0x9c, 0xff, 0xff,
+		0xff, 0x48, 0x3d, 0xe8, 0x3, 0x0, 0x0, 0xf, 0x83, 0xca, 0x0, 0x0, 0x0, 0x66, 0x43, 0x81, 0xac, 0x6c, 0x38,
+		0xff, 0xff, 0xff, 0x2, 0x0, 0x49, 0x8d, 0x85, 0x2, 0x0, 0x0, 0x0, 0x48, 0x3d, 0xe8, 0x3, 0x0, 0x0, 0xf,
+		0x83, 0xbe, 0x0, 0x0, 0x0, 0x49, 0x8b, 0x40, 0x18, 0x43, 0xf, 0xb6, 0x54, 0x6c, 0x4, 0x41, 0x88, 0x54, 0x0,
+		0x28, 0x48, 0xff, 0xc0, 0x49, 0x89, 0x40, 0x18, 0x48, 0x3d, 0x0, 0x10, 0x0, 0x0, 0x72, 0x18, 0x4d, 0x89, 0x68,
+		0x8, 0x4d, 0x89, 0x48, 0x20, 0x48, 0x8d, 0x5, 0x9, 0x0, 0x0, 0x0, 0x49, 0x89, 0x0, 0xb8, 0x2, 0x0, 0x0,
+		0x0, 0xc3, 0x49, 0x81, 0xed, 0x1, 0x0, 0x0, 0x0, 0x49, 0x81, 0xfd, 0xe8, 0x3, 0x0, 0x0, 0xf, 0x83, 0x86,
+		0x0, 0x0, 0x0, 0x4d, 0x89, 0x68, 0x8, 0x4d, 0x89, 0x48, 0x20, 0x48, 0x8d, 0x5, 0x9, 0x0, 0x0, 0x0, 0x49,
+		0x89, 0x0, 0xb8, 0x3, 0x0, 0x0, 0x0, 0xc3, 0x41, 0x8b, 0x40, 0x10, 0x83, 0xf8, 0x1, 0x75, 0xa, 0x43, 0xf,
+		0xb6, 0x4, 0x6c, 0x66, 0x43, 0x89, 0x4, 0x6c, 0x49, 0x81, 0xc5, 0x1, 0x0, 0x0, 0x0, 0x49, 0x8d, 0x85, 0x3,
+		0x0, 0x0, 0x0, 0x48, 0x3d, 0xe8, 0x3, 0x0, 0x0, 0xf, 0x83, 0x4f, 0x0, 0x0, 0x0, 0x66, 0x43, 0xc7, 0x44,
+		0x6c, 0x6, 0x0, 0x0, 0x31, 0xc0, 0xc3, 0xb8, 0x1, 0x0, 0x0, 0x0, 0xbb, 0x0, 0x0, 0x0, 0x0, 0x49, 0x8d,
+		0x8d, 0x1, 0x0, 0x0, 0x0, 0xc3, 0xb8, 0x1, 0x0, 0x0, 0x0, 0xbb, 0x1, 0x0, 0x0, 0x0, 0x49, 0x8d, 0x8d,
+		0x9c, 0xff, 0xff, 0xff, 0xc3, 0xb8, 0x1, 0x0, 0x0, 0x0, 0xbb, 0x2, 0x0, 0x0, 0x0, 0x49, 0x8d, 0x8d, 0x2,
+		0x0, 0x0, 0x0, 0xc3, 0xb8, 0x1, 0x0, 0x0, 0x0, 0xbb, 0x3, 0x0, 0x0, 0x0, 0x4c, 0x89, 0xe9, 0xc3, 0xb8,
+		0x1, 0x0, 0x0, 0x0, 0xbb, 0x4, 0x0, 0x0, 0x0, 0x49, 0x8d, 0x8d, 0x3, 0x0, 0x0, 0x0, 0xc3,
+	}, jit.code)
+}
+
+func TestJit_CompileConstants(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.Set, Value: 72, Offset: 1},
+		{Name: instructions.Set, Value: -1},
+		{Name: instructions.WriteString, Text: "Hi"},
+	}
+
+	jit := NewJit(execution.Options{MemorySize: 1000, CellSize: 16, BoundsCheck: true})
+	err := jit.Compile(testInstructions)
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, []byte{
+		0x49, 0x89, 0xc4, 0x49, 0x89, 0xd8, 0x4d, 0x8b, 0x68, 0x8, 0x4d, 0x8b, 0x48, 0x20, 0x49, 0x8b, 0x0, 0x48, 0x85,
+		0xc0, 0x74, 0x2, 0xff, 0xe0, 0x49, 0x8d, 0x85, 0x1, 0x0, 0x0, 0x0, 0x48, 0x3d, 0xe8, 0x3, 0x0, 0x0, 0xf,
+		0x83, 0x2f, 0x0, 0x0, 0x0, 0x66, 0x43, 0xc7, 0x44, 0x6c, 0x2, 0x48, 0x0, 0x66, 0x43, 0xc7, 0x4, 0x6c, 0xff,
+		0xff, 0xbb, 0x2, 0x0, 0x0, 0x0, 0x4d, 0x89, 0x68, 0x8, 0x4d, 0x89, 0x48, 0x20, 0x48, 0x8d, 0x5, 0x9, 0x0,
+		0x0, 0x0, 0x49, 0x89, 0x0, 0xb8, 0x5, 0x0, 0x0, 0x0, 0xc3, 0x31, 0xc0, 0xc3, 0xb8, 0x1, 0x0, 0x0, 0x0,
+		0xbb, 0x0, 0x0, 0x0, 0x0, 0x49, 0x8d, 0x8d, 0x1, 0x0, 0x0, 0x0, 0xc3,
+	}, jit.code)
+}
+
+func TestJit_CompileScan(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.ScanRight, Value: 1},
+		{Name: instructions.ScanLeft, Value: 1},
+		{Name: instructions.ScanRight, Value: 3},
+	}
+
+	var tests = []struct {
+		name     string
+		options  execution.Options
+		expected []byte
+	}{
+		{
+			"vector",
+			execution.Options{MemorySize: 1000},
+			[]byte{
+				0x49, 0x89, 0xc4, 0x49, 0x89, 0xd8, 0x4d, 0x8b, 0x68, 0x8, 0x4d, 0x8b, 0x48, 0x20, 0x49, 0x8b, 0x0, 0x48, 0x85,
+				0xc0, 0x74, 0x2, 0xff, 0xe0, 0x4b, 0x8d, 0x4, 0x2c, 0x89, 0xc1, 0x83, 0xe1, 0xf, 0x48, 0x83, 0xe0, 0xf0, 0x66,
+				0xf, 0xef, 0xc0, 0x66, 0xf, 0x6f, 0x8, 0x66, 0xf, 0x74, 0xc8, 0x66, 0xf, 0xd7, 0xd1, 0xbe, 0xff, 0xff, 0xff,
+				0xff, 0xd3, 0xe6, 0x21, 0xf2, 0x85, 0xd2, 0x75, 0x14, 0x48, 0x83, 0xc0, 0x10, 0x66, 0xf, 0x6f, 0x8, 0x66, 0xf,
+				0x74, 0xc8, 0x66, 0xf, 0xd7, 0xd1, 0x85, 0xd2, 0x74, 0xec, 0xf, 0xbc, 0xd2, 0x48, 0x1, 0xd0, 0x4c, 0x29, 0xe0,
+				0x49, 0x89, 0xc5, 0x4b, 0x8d, 0x4, 0x2c, 0x89, 0xc1, 0x83, 0xe1, 0xf, 0x48, 0x83, 0xe0, 0xf0, 0x66, 0xf, 0xef,
+				0xc0, 0x66, 0xf, 0x6f, 0x8, 0x66, 0xf, 0x74, 0xc8, 0x66, 0xf, 0xd7, 0xd1, 0xbe, 0x2, 0x0, 0x0, 0x0, 0xd3,
+				0xe6, 0xff, 0xce, 0x21, 0xf2, 0x85, 0xd2, 0x75, 0x14, 0x48, 0x83, 0xe8, 0x10, 0x66, 0xf, 0x6f, 0x8, 0x66, 0xf,
+				0x74, 0xc8, 0x66, 0xf, 0xd7, 0xd1, 0x85, 0xd2, 0x74, 0xec, 0xf, 0xbd, 0xd2, 0x48, 0x1, 0xd0, 0x4c, 0x29, 0xe0,
+				0x49, 0x89, 0xc5, 0xeb, 0x7, 0x49, 0x81, 0xc5, 0x3, 0x0, 0x0, 0x0, 0x43, 0x80, 0x3c, 0x2c, 0x0, 0x75, 0xf2,
+				0x31, 0xc0, 0xc3,
+			},
+		},
+		{
+			"bounds check",
+			execution.Options{MemorySize: 1000, BoundsCheck: true},
+			[]byte{
+				0x49, 0x89, 0xc4, 0x49, 0x89, 0xd8, 0x4d, 0x8b, 0x68, 0x8, 0x4d, 0x8b, 0x48, 0x20, 0x49, 0x8b, 0x0, 0x48, 0x85,
+				0xc0, 0x74, 0x2, 0xff, 0xe0, 0xeb, 0x14, 0x49, 0x81, 0xc5, 0x1, 0x0, 0x0, 0x0, 0x49, 0x81, 0xfd, 0xe8, 0x3,
+				0x0, 0x0, 0xf, 0x83, 0x44, 0x0, 0x0, 0x0, 0x43, 0x80, 0x3c, 0x2c, 0x0, 0x75, 0xe5, 0xeb, 0x14, 0x49, 0x81,
+				0xed, 0x1, 0x0, 0x0, 0x0, 0x49, 0x81, 0xfd, 0xe8, 0x3, 0x0, 0x0, 0xf, 0x83, 0x35, 0x0, 0x0, 0x0, 0x43,
+				0x80, 0x3c, 0x2c, 0x0, 0x75, 0xe5, 0xeb, 0x14, 0x49, 0x81, 0xc5, 0x3, 0x0, 0x0, 0x0, 0x49, 0x81, 0xfd, 0xe8,
+				0x3, 0x0, 0x0, 0xf, 0x83, 0x26, 0x0, 0x0, 0x0, 0x43, 0x80, 0x3c, 0x2c, 0x0, 0x75, 0xe5, 0x31, 0xc0, 0xc3,
+				0xb8, 0x1, 0x0, 0x0, 0x0, 0xbb, 0x0, 0x0, 0x0, 0x0, 0x4c, 0x89, 0xe9, 0xc3, 0xb8, 0x1, 0x0, 0x0, 0x0,
+				0xbb, 0x1, 0x0, 0x0, 0x0, 0x4c, 0x89, 0xe9, 0xc3, 0xb8, 0x1, 0x0, 0x0, 0x0, 0xbb, 0x2, 0x0, 0x0, 0x0,
+				0x4c, 0x89, 0xe9, 0xc3,
+			},
+		},
+		{
+			"simple codegen",
+			execution.Options{MemorySize: 1000, SimpleCodegen: true},
+			[]byte{
+				0x49, 0x89, 0xc4, 0x49, 0x89, 0xd8, 0x4d, 0x8b, 0x68, 0x8, 0x4d, 0x8b, 0x48, 0x20, 0x49, 0x8b, 0x0, 0x48, 0x85,
+				0xc0, 0x74, 0x2, 0xff, 0xe0, 0xeb, 0x7, 0x49, 0x81, 0xc5, 0x1, 0x0, 0x0, 0x0, 0x43, 0x80, 0x3c, 0x2c, 0x0,
+				0x75, 0xf2, 0xeb, 0x7, 0x49, 0x81, 0xed, 0x1, 0x0, 0x0, 0x0, 0x43, 0x80, 0x3c, 0x2c, 0x0, 0x75, 0xf2, 0xeb,
+				0x7, 0x49, 0x81, 0xc5, 0x3, 0x0, 0x0, 0x0, 0x43, 0x80, 0x3c, 0x2c, 0x0, 0x75, 0xf2, 0x31, 0xc0, 0xc3,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jit := NewJit(test.options)
+			err := jit.Compile(testInstructions)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, jit.code)
+		})
+	}
+}
+
+func TestJit_CompileCellSizes(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.Increment, Value: 0x10001},
+		{Name: instructions.Decrement, Value: 1},
+		{Name: instructions.Read, Value: 1},
+		{Name: instructions.JumpIfZero, Value: 5},
+		{Name: instructions.Clear},
+		{Name: instructions.JumpUnlessZero, Value: 3},
+	}
+
+	var tests = []struct {
+		name     string
+		cellSize uint
+		expected []byte
+	}{
+		{
+			"16-bit",
+			16,
+			[]byte{
+				0x49, 0x89, 0xc4, 0x49, 0x89, 0xd8, 0x4d, 0x8b, 0x68, 0x8, 0x4d, 0x8b, 0x48, 0x20, 0x49, 0x8b, 0x0, 0x48, 0x85,
+				0xc0, 0x74, 0x2, 0xff, 0xe0, 0x66, 0x43, 0x81, 0x4, 0x6c, 0x1, 0x0, 0x66, 0x43, 0x81, 0x2c, 0x6c, 0x1, 0x0,
+				0x4d, 0x89, 0x68, 0x8, 0x4d, 0x89, 0x48, 0x20, 0x48, 0x8d, 0x5, 0x9, 0x0, 0x0, 0x0, 0x49, 0x89, 0x0, 0xb8,
+				0x3, 0x0, 0x0, 0x0, 0xc3, 0x41, 0x8b, 0x40, 0x10, 0x83, 0xf8, 0x1, 0x75, 0xa, 0x43, 0xf, 0xb6, 0x4, 0x6c,
+				0x66, 0x43, 0x89, 0x4, 0x6c, 0x66, 0x43, 0x81, 0x3c, 0x6c, 0x0, 0x0, 0xf, 0x84, 0x1d, 0x0, 0x0, 0x0, 0x66,
+				0x43, 0xc7, 0x4, 0x6c, 0x0, 0x0, 0x49, 0xff, 0xc9, 0xf, 0x84, 0x10, 0x0, 0x0, 0x0, 0x66, 0x43, 0x81, 0x3c,
+				0x6c, 0x0, 0x0, 0xf, 0x85, 0xe3, 0xff, 0xff, 0xff, 0x31, 0xc0, 0xc3, 0x4d, 0x89, 0x68, 0x8, 0x4d, 0x89, 0x48,
+				0x20, 0x48, 0x8d, 0x5, 0xe1, 0xff, 0xff, 0xff, 0x49, 0x89, 0x0, 0xb8, 0x4, 0x0, 0x0, 0x0, 0xc3,
+			},
+		},
+		{
+			"32-bit",
+			32,
+			[]byte{
+				0x49, 0x89, 0xc4, 0x49, 0x89, 0xd8, 0x4d, 0x8b, 0x68, 0x8, 0x4d, 0x8b, 0x48, 0x20, 0x49, 0x8b, 0x0, 0x48, 0x85,
+				0xc0, 0x74, 0x2, 0xff, 0xe0, 0x43, 0x81, 0x4, 0xac, 0x1, 0x0, 0x1, 0x0, 0x43, 0x81, 0x2c, 0xac, 0x1, 0x0,
+				0x0, 0x0, 0x4d, 0x89, 0x68, 0x8, 0x4d, 0x89, 0x48, 0x20, 0x48, 0x8d, 0x5, 0x9, 0x0, 0x0, 0x0, 0x49, 0x89,
+				0x0, 0xb8, 0x3, 0x0, 0x0, 0x0, 0xc3, 0x41, 0x8b, 0x40, 0x10, 0x83, 0xf8, 0x1, 0x75, 0x9, 0x43, 0xf, 0xb6,
+				0x4, 0xac, 0x43, 0x89, 0x4, 0xac, 0x43, 0x81, 0x3c, 0xac, 0x0, 0x0, 0x0, 0x0, 0xf, 0x84, 0x1f, 0x0, 0x0,
+				0x0, 0x43, 0xc7, 0x4, 0xac, 0x0, 0x0, 0x0, 0x0, 0x49, 0xff, 0xc9, 0xf, 0x84, 0x11, 0x0, 0x0, 0x0, 0x43,
+				0x81, 0x3c, 0xac, 0x0, 0x0, 0x0, 0x0, 0xf, 0x85, 0xe1, 0xff, 0xff, 0xff, 0x31, 0xc0, 0xc3, 0x4d, 0x89, 0x68,
+				0x8, 0x4d, 0x89, 0x48, 0x20, 0x48, 0x8d, 0x5, 0xe0, 0xff, 0xff, 0xff, 0x49, 0x89, 0x0, 0xb8, 0x4, 0x0, 0x0,
+				0x0, 0xc3,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jit := NewJit(execution.Options{MemorySize: 1000, CellSize: test.cellSize})
+			err := jit.Compile(testInstructions)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, jit.code)
+		})
+	}
+}
+
+func TestJit_CompileUnbuffered(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.Read, Value: 1},
+		{Name: instructions.Write, Value: 1},
+	}
+
+	jit := NewJit(execution.Options{MemorySize: 1000, Unbuffered: true})
+	err := jit.Compile(testInstructions)
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, []byte{
+		0x49, 0x89, 0xc4, 0x49, 0x89, 0xd8, 0x4d, 0x8b, 0x68, 0x8, 0x4d, 0x8b, 0x48, 0x20, 0x49, 0x8b, 0x0, 0x48, 0x85,
+		0xc0, 0x74, 0x2, 0xff, 0xe0, 0x4d, 0x89, 0x68, 0x8, 0x4d, 0x89, 0x48, 0x20, 0x48, 0x8d, 0x5, 0x9, 0x0, 0x0,
+		0x0, 0x49, 0x89, 0x0, 0xb8, 0x3, 0x0, 0x0, 0x0, 0xc3, 0x41, 0x8b, 0x40, 0x10, 0x49, 0x8b, 0x40, 0x18, 0x43,
+		0xf, 0xb6, 0x14, 0x2c, 0x41, 0x88, 0x54, 0x0, 0x28, 0x48, 0xff, 0xc0, 0x49, 0x89, 0x40, 0x18, 0x4d, 0x89, 0x68,
+		0x8, 0x4d, 0x89, 0x48, 0x20, 0x48, 0x8d, 0x5, 0x9, 0x0, 0x0, 0x0, 0x49, 0x89, 0x0, 0xb8, 0x2, 0x0, 0x0,
+		0x0, 0xc3, 0x31, 0xc0, 0xc3,
+	}, jit.code)
+}
+
+func TestJit_CompileEOF(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.Read, Value: 1},
+	}
+
+	var tests = []struct {
+		name     string
+		options  execution.Options
+		expected []byte
+	}{
+		{
+			"minus-one",
+			execution.Options{MemorySize: 1000, EOF: execution.EOFMinusOne},
+			[]byte{
+				0x49, 0x89, 0xc4, 0x49, 0x89, 0xd8, 0x4d, 0x8b, 0x68, 0x8, 0x4d, 0x8b, 0x48, 0x20, 0x49, 0x8b, 0x0, 0x48, 0x85,
+				0xc0, 0x74, 0x2, 0xff, 0xe0, 0x4d, 0x89, 0x68, 0x8, 0x4d, 0x89, 0x48, 0x20, 0x48, 0x8d, 0x5, 0x9, 0x0, 0x0,
+				0x0, 0x49, 0x89, 0x0, 0xb8, 0x3, 0x0, 0x0, 0x0, 0xc3, 0x41, 0x8b, 0x40, 0x10, 0x83, 0xf8, 0x1, 0x74, 0x5,
+				0x43, 0xc6, 0x4, 0x2c, 0xff, 0x31, 0xc0, 0xc3,
+			},
+		},
+		{
+			"zero 16-bit",
+			execution.Options{MemorySize: 1000, CellSize: 16, EOF: execution.EOFZero},
+			[]byte{
+				0x49, 0x89, 0xc4, 0x49, 0x89, 0xd8, 0x4d, 0x8b, 0x68, 0x8, 0x4d, 0x8b, 0x48, 0x20, 0x49, 0x8b, 0x0, 0x48, 0x85,
+				0xc0, 0x74, 0x2, 0xff, 0xe0, 0x4d, 0x89, 0x68, 0x8, 0x4d, 0x89, 0x48, 0x20, 0x48, 0x8d, 0x5, 0x9, 0x0, 0x0,
+				0x0, 0x49, 0x89, 0x0, 0xb8, 0x3, 0x0, 0x0, 0x0, 0xc3, 0x41, 0x8b, 0x40, 0x10, 0x83, 0xf8, 0x1, 0x74, 0x7,
+				0x66, 0x43, 0xc7, 0x4, 0x6c, 0x0, 0x0, 0x83, 0xf8, 0x1, 0x75, 0xa, 0x43, 0xf, 0xb6, 0x4, 0x6c, 0x66, 0x43,
+				0x89, 0x4, 0x6c, 0x31, 0xc0, 0xc3,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jit := NewJit(test.options)
+			err := jit.Compile(testInstructions)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, jit.code)
+		})
+	}
+}
+
+func TestJit_Disassemble(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.JumpIfZero, Value: 2, Span: instructions.NewSpan(instructions.Position{Line: 1, Column: 1})},
+		{Name: instructions.Decrement, Value: 1, Offset: 1},
+		{Name: instructions.JumpUnlessZero, Value: 0},
+	}
+
+	jit := NewJit(execution.Options{MemorySize: 1000, CellSize: 16, BoundsCheck: true})
+	assert.NoError(t, jit.Compile(testInstructions))
+
+	assert.Equal(t, `prologue:
+  0000  49 89 c4                 mov     r12, rax
+  0003  49 89 d8                 mov     r8, rbx
+  0006  4d 8b 68 08              mov     r13, QWORD PTR [r8+0x8]
+  000a  4d 8b 48 20              mov     r9, QWORD PTR [r8+0x20]
+  000e  49 8b 00                 mov     rax, QWORD PTR [r8]
+  0011  48 85 c0                 test    rax, rax
+  0014  74 02                    je      0x18
+  0016  ff e0                    jmp     rax
+0 JumpIfZero -> 2 (1:1):
+  0018  66 43 81 3c 6c 00 00     cmp     WORD PTR [r12+r13*2], 0x0
+  001f  0f 84 31 00 00 00        je      0x56
+1 Decrement 1 [+1]:
+  0025  49 8d 85 01 00 00 00     lea     rax, [r13+0x1]
+  002c  48 3d e8 03 00 00        cmp     rax, 0x3e8
+  0032  0f 83 21 00 00 00        jae     0x59
+  0038  66 43 81 6c 6c 02 01 00  sub     WORD PTR [r12+r13*2+0x2], 0x1
+2 JumpUnlessZero -> 0:
+  0040  49 ff c9                 dec     r9
+  0043  0f 84 22 00 00 00        je      0x6b
+  0049  66 43 81 3c 6c 00 00     cmp     WORD PTR [r12+r13*2], 0x0
+  0050  0f 85 cf ff ff ff        jne     0x25
+epilogue:
+  0056  31 c0                    xor     eax, eax
+  0058  c3                       ret
+out of bounds stub for 1 Decrement 1 [+1]:
+  0059  b8 01 00 00 00           mov     eax, 0x1
+  005e  bb 01 00 00 00           mov     ebx, 0x1
+  0063  49 8d 8d 01 00 00 00     lea     rcx, [r13+0x1]
+  006a  c3                       ret
+yield stub for 2 JumpUnlessZero -> 0:
+  006b  4d 89 68 08              mov     QWORD PTR [r8+0x8], r13
+  006f  4d 89 48 20              mov     QWORD PTR [r8+0x20], r9
+  0073  48 8d 05 cf ff ff ff     lea     rax, [rip-0x31]  # 0x49
+  007a  49 89 00                 mov     QWORD PTR [r8], rax
+  007d  b8 04 00 00 00           mov     eax, 0x4
+  0082  c3                       ret
+`, jit.Disassemble())
+}
+
+func TestJit_DecodeAmd64(t *testing.T) {
+	var tests = []struct {
+		code     []byte
+		size     int
+		expected string
+	}{
+		{[]byte{0x48, 0xb8, 0x1, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x80}, 10, "movabs  rax, 0x8000000000000001"},
+		{[]byte{0x66, 0x0f, 0x6f, 0x08}, 4, "movdqa  xmm1, XMMWORD PTR [rax]"},
+		{[]byte{0x48, 0x83, 0xe0, 0xf0}, 4, "and     rax, 0xfffffffffffffff0"},
+		{[]byte{0x48, 0x8d, 0x05, 0xf0, 0xff, 0xff, 0xff}, 7, "lea     rax, [rip-0x10]  # 0xf7"},
+		{[]byte{0xeb, 0xfe}, 2, "jmp     0x100"},
+		{[]byte{0xff, 0xe0}, 2, "jmp     rax"},
+		{[]byte{0x0f, 0x0b}, 1, "(bad)"},
+		{[]byte{0x43, 0x80, 0x04}, 1, "(bad)"},
+	}
+
+	for _, test := range tests {
+		size, text := decodeAmd64(test.code, 0x100)
+		assert.Equal(t, test.size, size)
+		assert.Equal(t, test.expected, text)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !(darwin && arm64) && !(linux && (amd64 || arm64))
+
+package jit
+
+import (
+	"context"
+	"gobf/instructions"
+	"io"
+)
+
+func (jit *Jit) Compile(parsedInstructions []instructions.Instruction) error {
+	return ErrUnsupportedPlatform
+}
+
+func (jit *Jit) Run(ctx context.Context, input io.Reader, output io.Writer) error {
+	return ErrUnsupportedPlatform
+}
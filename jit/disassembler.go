@@ -0,0 +1,93 @@
+package jit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// decoder disassembles the machine instruction at the start of code, which is located at address in the generated
+// code. It returns the length of the instruction and its mnemonic with operands, branch targets are absolute addresses
+type decoder func(code []byte, address int) (int, string)
+
+// Disassemble returns a listing of the generated code with the address, bytes and mnemonic of every machine
+// instruction, grouped under the instruction it was generated for. The prologue, epilogue and stubs are labelled too
+func (jit *Jit) Disassemble() string {
+	if jit.decode == nil {
+		return ""
+	}
+
+	var listing strings.Builder
+
+	addressWidth := len(fmt.Sprintf("%x", len(jit.code)))
+	if addressWidth < 4 {
+		addressWidth = 4
+	}
+
+	bytesWidth := 0
+	for address := 0; address < len(jit.code); {
+		size, _ := jit.decode(jit.code[address:], address)
+		if size*3 > bytesWidth {
+			bytesWidth = size * 3
+		}
+
+		address += size
+	}
+
+	disassemble := func(label string, start int, end int) {
+		listing.WriteString(label + ":\n")
+
+		for address := start; address < end; {
+			size, text := jit.decode(jit.code[address:end], address)
+
+			encoded := make([]string, size)
+			for i, b := range jit.code[address : address+size] {
+				encoded[i] = fmt.Sprintf("%02x", b)
+			}
+
+			listing.WriteString(fmt.Sprintf("  %0*x  %-*s %s\n", addressWidth, address, bytesWidth, strings.Join(encoded, " "), text))
+
+			address += size
+		}
+	}
+
+	stubs := len(jit.code)
+	if len(jit.stubs) > 0 {
+		stubs = jit.stubs[0].offset
+	}
+
+	prologue := jit.epilogue
+	if len(jit.codeBlocks) > 0 {
+		prologue = jit.codeBlocks[0].offset
+	}
+
+	disassemble("prologue", 0, prologue)
+
+	for index, block := range jit.codeBlocks {
+		disassemble(jit.describeInstruction(index), block.offset, block.end)
+	}
+
+	disassemble("epilogue", jit.epilogue, stubs)
+
+	for _, stub := range jit.stubs {
+		disassemble(stub.description+" stub for "+jit.describeInstruction(stub.instruction), stub.offset, stub.end)
+	}
+
+	return listing.String()
+}
+
+// describeInstruction returns the index of an instruction with its description and the source code it was created from
+func (jit *Jit) describeInstruction(index int) string {
+	instruction := jit.codeBlocks[index].instruction
+
+	description := fmt.Sprintf("%d %s", index, instruction.Describe())
+	if !instruction.Span.IsZero() {
+		description += " (" + instruction.Span.String() + ")"
+	}
+
+	return description
+}
+
+// formatInstruction returns a mnemonic followed by its operands, with the operands lined up between instructions
+func formatInstruction(mnemonic string, operands ...string) string {
+	return fmt.Sprintf("%-7s %s", mnemonic, strings.Join(operands, ", "))
+}
@@ -0,0 +1,205 @@
+package jit
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+var aarch64Conditions = [16]string{"eq", "ne", "hs", "lo", "mi", "pl", "vs", "vc", "hi", "ls", "ge", "lt", "gt", "le", "al", "nv"}
+
+var aarch64Shifts = [4]string{"lsl", "lsr", "asr", "ror"}
+
+// Loads and stores of a general purpose register by size (bits 31:30) and whether it loads (bit 22)
+var aarch64LoadsAndStores = [4][2]string{{"strb", "ldrb"}, {"strh", "ldrh"}, {"str", "ldr"}, {"str", "ldr"}}
+
+// decodeAarch64 decodes the subset of AArch64 instructions generated by the JIT, printed like llvm-mc except that
+// branch targets are absolute addresses
+func decodeAarch64(code []byte, address int) (int, string) {
+	if len(code) < 4 {
+		return len(code), "(bad)"
+	}
+
+	instruction := binary.LittleEndian.Uint32(code)
+
+	text := decodeAarch64Instruction(instruction, address)
+	if text == "" {
+		return 4, "(bad)"
+	}
+
+	return 4, text
+}
+
+func decodeAarch64Instruction(instruction uint32, address int) string {
+	// fields shared by most instructions: sf (bit 31) selects 64-bit registers, Rn (bits 9:5) and Rd or Rt (bits 4:0)
+	wide := instruction>>31 != 0
+	rn := int(instruction >> 5 & 0x1f)
+	rd := int(instruction & 0x1f)
+
+	switch {
+	case instruction&0x7f800000 == 0x52800000:
+		// movz, shifting imm16 (bits 20:5) left by 16 times hw (bits 22:21)
+		immediate := uint64(instruction>>5&0xffff) << (instruction >> 21 & 0x3 * 16)
+		return formatInstruction("mov", aarch64Register(rd, wide, false), fmt.Sprintf("#%d", immediate))
+	case instruction&0x7f800000 == 0x72800000:
+		operands := []string{aarch64Register(rd, wide, false), fmt.Sprintf("#%d", instruction>>5&0xffff)}
+		if shift := instruction >> 21 & 0x3 * 16; shift != 0 {
+			operands = append(operands, fmt.Sprintf("lsl #%d", shift))
+		}
+
+		return formatInstruction("movk", operands...)
+	case instruction&0x1f800000 == 0x11000000:
+		// add and sub with imm12 (bits 21:10), shifted left by 12 when sh (bit 22) is set
+		operands := []string{aarch64Register(rn, wide, true), fmt.Sprintf("#%d", instruction>>10&0xfff)}
+		if instruction&(1<<22) != 0 {
+			operands = append(operands, "lsl #12")
+		}
+
+		return aarch64Arithmetic(instruction, rd, wide, true, operands)
+	case instruction&0x1f200000 == 0x0b000000:
+		// add and sub with Rm (bits 20:16), shifted by imm6 (bits 15:10)
+		operands := []string{aarch64Register(rn, wide, false), aarch64Register(int(instruction>>16&0x1f), wide, false)}
+		if amount := instruction >> 10 & 0x3f; amount != 0 {
+			operands = append(operands, fmt.Sprintf("%s #%d", aarch64Shifts[instruction>>22&0x3], amount))
+		}
+
+		return aarch64Arithmetic(instruction, rd, wide, false, operands)
+	case instruction&0x7f200000 == 0x2a000000:
+		// orr with a shifted register, which is a move without a shift and with the zero register as Rn
+		rm := aarch64Register(int(instruction>>16&0x1f), wide, false)
+		amount := instruction >> 10 & 0x3f
+		if rn == 31 && amount == 0 {
+			return formatInstruction("mov", aarch64Register(rd, wide, false), rm)
+		}
+
+		operands := []string{aarch64Register(rd, wide, false), aarch64Register(rn, wide, false), rm}
+		if amount != 0 {
+			operands = append(operands, fmt.Sprintf("%s #%d", aarch64Shifts[instruction>>22&0x3], amount))
+		}
+
+		return formatInstruction("orr", operands...)
+	case instruction&0x7fe08000 == 0x1b000000:
+		// madd, Rd = Ra (bits 14:10) + Rn * Rm, which is a mul with the zero register as Ra
+		operands := []string{aarch64Register(rd, wide, false), aarch64Register(rn, wide, false), aarch64Register(int(instruction>>16&0x1f), wide, false)}
+		if ra := int(instruction >> 10 & 0x1f); ra != 31 {
+			return formatInstruction("madd", append(operands, aarch64Register(ra, wide, false))...)
+		}
+
+		return formatInstruction("mul", operands...)
+	case instruction&0x7c000000 == 0x14000000:
+		// b and bl with imm26 (bits 25:0), in instructions like all branch offsets
+		mnemonic := "b"
+		if wide {
+			mnemonic = "bl"
+		}
+
+		return formatInstruction(mnemonic, aarch64BranchTarget(address, instruction&0x3ffffff<<2, 28))
+	case instruction&0xff000010 == 0x54000000:
+		// b.cond with imm19 (bits 23:5) and the condition in bits 3:0
+		return formatInstruction("b."+aarch64Conditions[instruction&0xf], aarch64BranchTarget(address, instruction>>5&0x7ffff<<2, 21))
+	case instruction&0x7e000000 == 0x34000000:
+		// cbz and cbnz (bit 24) with imm19 (bits 23:5)
+		mnemonic := "cbz"
+		if instruction&(1<<24) != 0 {
+			mnemonic = "cbnz"
+		}
+
+		return formatInstruction(mnemonic, aarch64Register(rd, wide, false), aarch64BranchTarget(address, instruction>>5&0x7ffff<<2, 21))
+	case instruction&0xfffffc1f == 0xd61f0000:
+		return formatInstruction("br", aarch64Register(rn, true, false))
+	case instruction&0xfffffc1f == 0xd65f0000:
+		if rn == 30 {
+			return "ret"
+		}
+
+		return formatInstruction("ret", aarch64Register(rn, true, false))
+	case instruction&0x9f000000 == 0x10000000:
+		// adr with immhi (bits 23:5) and immlo (bits 30:29), in bytes
+		offset := instruction>>5&0x7ffff<<2 | instruction>>29&0x3
+		return formatInstruction("adr", aarch64Register(rd, true, false), aarch64BranchTarget(address, offset, 21))
+	case instruction&0x3f800000 == 0x39000000:
+		// loads and stores with imm12 (bits 21:10), scaled by the size
+		mnemonic, register := aarch64LoadOrStore(instruction, rd)
+
+		base := "[" + aarch64Register(rn, true, true)
+		if offset := instruction >> 10 & 0xfff << (instruction >> 30); offset != 0 {
+			base += fmt.Sprintf(", #%d", offset)
+		}
+
+		return formatInstruction(mnemonic, register, base+"]")
+	case instruction&0x3fa00c00 == 0x38200800:
+		// loads and stores with Rm (bits 20:16) extended by option (bits 15:13), shifted by the size when S (bit 12)
+		mnemonic, register := aarch64LoadOrStore(instruction, rd)
+		option := instruction >> 13 & 0x7
+
+		operand := "[" + aarch64Register(rn, true, true) + ", " + aarch64Register(int(instruction>>16&0x1f), option&0x1 != 0, false)
+
+		extend := map[uint32]string{2: "uxtw", 3: "lsl", 6: "sxtw", 7: "sxtx"}[option]
+		switch {
+		case extend == "":
+			return ""
+		case instruction&(1<<12) != 0:
+			operand += fmt.Sprintf(", %s #%d", extend, instruction>>30)
+		case extend != "lsl":
+			operand += ", " + extend
+		}
+
+		return formatInstruction(mnemonic, register, operand+"]")
+	}
+
+	return ""
+}
+
+// aarch64Arithmetic formats an add or sub, op (bit 30), which sets the flags when S (bit 29) is set. A compare is a sub
+// setting the flags with the zero register as Rd
+func aarch64Arithmetic(instruction uint32, rd int, wide bool, immediate bool, operands []string) string {
+	mnemonic := "add"
+	if instruction&(1<<30) != 0 {
+		mnemonic = "sub"
+	}
+
+	if instruction&(1<<29) != 0 {
+		if rd == 31 {
+			return formatInstruction(map[string]string{"add": "cmn", "sub": "cmp"}[mnemonic], operands...)
+		}
+
+		mnemonic += "s"
+	}
+
+	// Rd is the stack pointer for add and sub with an immediate which don't set the flags, and the zero register otherwise
+	return formatInstruction(mnemonic, append([]string{aarch64Register(rd, wide, immediate && instruction&(1<<29) == 0)}, operands...)...)
+}
+
+// aarch64LoadOrStore returns the mnemonic of a load or store and the register it loads or stores
+func aarch64LoadOrStore(instruction uint32, rt int) (string, string) {
+	size := instruction >> 30
+	load := instruction >> 22 & 0x1
+
+	return aarch64LoadsAndStores[size][load], aarch64Register(rt, size == 3, false)
+}
+
+// aarch64Register returns the name of a general purpose register, register 31 is either the stack pointer or the zero
+// register depending on the instruction
+func aarch64Register(register int, wide bool, stackPointer bool) string {
+	prefix := "w"
+	if wide {
+		prefix = "x"
+	}
+
+	if register == 31 {
+		if stackPointer {
+			return map[bool]string{false: "wsp", true: "sp"}[wide]
+		}
+
+		return prefix + "zr"
+	}
+
+	return fmt.Sprintf("%s%d", prefix, register)
+}
+
+// aarch64BranchTarget returns the address a branch jumps to, from its signed offset in bytes which is bits bits wide
+func aarch64BranchTarget(address int, offset uint32, bits int) string {
+	// sign extend the offset
+	signed := int(int32(offset<<(32-bits)) >> (32 - bits))
+
+	return fmt.Sprintf("%#x", address+signed)
+}
@@ -0,0 +1,373 @@
+//go:build linux && amd64
+
+package jit
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Register names by operand size in bytes, indexed by register number including the REX extension bit
+var amd64Registers = map[int][16]string{
+	1: {"al", "cl", "dl", "bl", "spl", "bpl", "sil", "dil", "r8b", "r9b", "r10b", "r11b", "r12b", "r13b", "r14b", "r15b"},
+	2: {"ax", "cx", "dx", "bx", "sp", "bp", "si", "di", "r8w", "r9w", "r10w", "r11w", "r12w", "r13w", "r14w", "r15w"},
+	4: {"eax", "ecx", "edx", "ebx", "esp", "ebp", "esi", "edi", "r8d", "r9d", "r10d", "r11d", "r12d", "r13d", "r14d", "r15d"},
+	8: {"rax", "rcx", "rdx", "rbx", "rsp", "rbp", "rsi", "rdi", "r8", "r9", "r10", "r11", "r12", "r13", "r14", "r15"},
+}
+
+var amd64PointerSizes = map[int]string{1: "BYTE PTR ", 2: "WORD PTR ", 4: "DWORD PTR ", 8: "QWORD PTR ", 16: "XMMWORD PTR "}
+
+// Operations of the arithmetic opcodes 0x00 to 0x3d and the 0x80 to 0x83 group, by the ModRM reg field
+var amd64Arithmetic = [8]string{"add", "or", "adc", "sbb", "and", "sub", "xor", "cmp"}
+
+var amd64Shifts = [8]string{"rol", "ror", "rcl", "rcr", "shl", "shr", "sal", "sar"}
+
+var amd64Conditions = [16]string{"o", "no", "b", "ae", "e", "ne", "be", "a", "s", "ns", "p", "np", "l", "ge", "le", "g"}
+
+// amd64Instruction is the state of decoding a single instruction, an out of range read marks it as truncated
+type amd64Instruction struct {
+	code      []byte
+	address   int
+	position  int
+	rex       byte
+	operand16 bool
+	truncated bool
+	// modrm fields, with the REX extension bits applied to reg
+	mod byte
+	reg int
+	rm  byte
+}
+
+// decodeAmd64 decodes the subset of x86_64 instructions generated by the JIT, printed in Intel syntax like objdump
+func decodeAmd64(code []byte, address int) (int, string) {
+	instruction := amd64Instruction{code: code, address: address}
+
+	text := instruction.decode()
+	if text == "" || instruction.truncated {
+		return 1, "(bad)"
+	}
+
+	return instruction.position, text
+}
+
+func (instruction *amd64Instruction) decode() string {
+	opcode := instruction.byte()
+
+	if opcode == 0x66 {
+		instruction.operand16 = true
+		opcode = instruction.byte()
+	}
+
+	if opcode&0xf0 == 0x40 {
+		instruction.rex = opcode
+		opcode = instruction.byte()
+	}
+
+	size := instruction.operandSize()
+
+	switch {
+	case opcode < 0x40 && opcode&0x07 < 4:
+		// arithmetic between a register and a register or memory operand, in either direction
+		if opcode&0x01 == 0 {
+			size = 1
+		}
+
+		memory := instruction.modrm(size)
+		if opcode&0x02 == 0 {
+			return formatInstruction(amd64Arithmetic[opcode>>3], memory, instruction.register(size))
+		}
+
+		return formatInstruction(amd64Arithmetic[opcode>>3], instruction.register(size), memory)
+	case opcode < 0x40 && opcode&0x07 == 5:
+		return formatInstruction(amd64Arithmetic[opcode>>3], amd64Registers[size][0], instruction.immediate(size, immediateSizeOf(size)))
+	case opcode == 0x69:
+		memory := instruction.modrm(size)
+		return formatInstruction("imul", instruction.register(size), memory, instruction.immediate(size, 4))
+	case opcode >= 0x70 && opcode < 0x80:
+		return formatInstruction("j"+amd64Conditions[opcode&0x0f], instruction.branchTarget(1))
+	case opcode >= 0x80 && opcode <= 0x83:
+		if opcode == 0x80 {
+			size = 1
+		}
+
+		immediateSize := 1
+		if opcode == 0x81 {
+			immediateSize = immediateSizeOf(size)
+		}
+
+		memory := instruction.modrm(size)
+		return formatInstruction(amd64Arithmetic[instruction.reg&0x07], memory, instruction.immediate(size, immediateSize))
+	case opcode == 0x84 || opcode == 0x85 || opcode == 0x88 || opcode == 0x89:
+		if opcode&0x01 == 0 {
+			size = 1
+		}
+
+		mnemonic := "test"
+		if opcode >= 0x88 {
+			mnemonic = "mov"
+		}
+
+		memory := instruction.modrm(size)
+		return formatInstruction(mnemonic, memory, instruction.register(size))
+	case opcode == 0x8b:
+		memory := instruction.modrm(size)
+		return formatInstruction("mov", instruction.register(size), memory)
+	case opcode == 0x8d:
+		// the address is calculated, not loaded, so it has no size
+		memory := instruction.modrm(0)
+		return formatInstruction("lea", instruction.register(size), memory)
+	case opcode >= 0xb8 && opcode <= 0xbf:
+		register := amd64Registers[size][int(opcode&0x07)|instruction.rexBit(0x01)]
+		if size == 8 {
+			return formatInstruction("movabs", register, instruction.immediate(8, 8))
+		}
+
+		return formatInstruction("mov", register, instruction.immediate(size, size))
+	case opcode == 0xc3:
+		return "ret"
+	case opcode == 0xc6 || opcode == 0xc7:
+		if opcode == 0xc6 {
+			size = 1
+		}
+
+		memory := instruction.modrm(size)
+		if instruction.reg&0x07 != 0 {
+			return ""
+		}
+
+		return formatInstruction("mov", memory, instruction.immediate(size, immediateSizeOf(size)))
+	case opcode == 0xd3:
+		memory := instruction.modrm(size)
+		return formatInstruction(amd64Shifts[instruction.reg&0x07], memory, "cl")
+	case opcode == 0xeb:
+		return formatInstruction("jmp", instruction.branchTarget(1))
+	case opcode == 0xff:
+		// indirect jumps always use 64-bit operands
+		if instruction.position < len(instruction.code) && instruction.code[instruction.position]>>3&0x07 == 4 {
+			return formatInstruction("jmp", instruction.modrm(8))
+		}
+
+		memory := instruction.modrm(size)
+
+		switch instruction.reg & 0x07 {
+		case 0:
+			return formatInstruction("inc", memory)
+		case 1:
+			return formatInstruction("dec", memory)
+		}
+	case opcode == 0x0f:
+		return instruction.decodeTwoByte(size)
+	}
+
+	return ""
+}
+
+// decodeTwoByte decodes the instructions with an opcode starting with 0x0f
+func (instruction *amd64Instruction) decodeTwoByte(size int) string {
+	opcode := instruction.byte()
+
+	switch {
+	case opcode >= 0x80 && opcode < 0x90:
+		return formatInstruction("j"+amd64Conditions[opcode&0x0f], instruction.branchTarget(4))
+	case opcode == 0xb6 || opcode == 0xb7:
+		sourceSize := 1
+		if opcode == 0xb7 {
+			sourceSize = 2
+		}
+
+		memory := instruction.modrm(sourceSize)
+		return formatInstruction("movzx", instruction.register(size), memory)
+	case opcode == 0xbc || opcode == 0xbd:
+		mnemonic := "bsf"
+		if opcode == 0xbd {
+			mnemonic = "bsr"
+		}
+
+		memory := instruction.modrm(size)
+		return formatInstruction(mnemonic, instruction.register(size), memory)
+	}
+
+	// SSE2 instructions on xmm registers, selected by the operand size prefix
+	if !instruction.operand16 {
+		return ""
+	}
+
+	switch opcode {
+	case 0x6f, 0x74, 0xef:
+		mnemonic := map[byte]string{0x6f: "movdqa", 0x74: "pcmpeqb", 0xef: "pxor"}[opcode]
+
+		memory := instruction.modrmXmm(16)
+		return formatInstruction(mnemonic, fmt.Sprintf("xmm%d", instruction.reg), memory)
+	case 0xd7:
+		memory := instruction.modrmXmm(16)
+		return formatInstruction("pmovmskb", amd64Registers[4][instruction.reg], memory)
+	}
+
+	return ""
+}
+
+// byte reads the next byte of the instruction
+func (instruction *amd64Instruction) byte() byte {
+	if instruction.position >= len(instruction.code) {
+		instruction.truncated = true
+		return 0
+	}
+
+	instruction.position++
+
+	return instruction.code[instruction.position-1]
+}
+
+// signed reads a little endian signed integer of size bytes
+func (instruction *amd64Instruction) signed(size int) int64 {
+	if instruction.position+size > len(instruction.code) {
+		instruction.truncated = true
+		return 0
+	}
+
+	bytes := instruction.code[instruction.position : instruction.position+size]
+	instruction.position += size
+
+	switch size {
+	case 1:
+		return int64(int8(bytes[0]))
+	case 2:
+		return int64(int16(binary.LittleEndian.Uint16(bytes)))
+	case 4:
+		return int64(int32(binary.LittleEndian.Uint32(bytes)))
+	}
+
+	return int64(binary.LittleEndian.Uint64(bytes))
+}
+
+// immediate reads a signed immediate of immediateSize bytes, shown sign extended to the operand size
+func (instruction *amd64Instruction) immediate(size int, immediateSize int) string {
+	value := uint64(instruction.signed(immediateSize))
+	if size < 8 {
+		value &= 1<<(size*8) - 1
+	}
+
+	return fmt.Sprintf("%#x", value)
+}
+
+// branchTarget reads a relative branch displacement of size bytes, and returns the address it branches to
+func (instruction *amd64Instruction) branchTarget(size int) string {
+	displacement := instruction.signed(size)
+
+	return fmt.Sprintf("%#x", int64(instruction.address+instruction.position)+displacement)
+}
+
+// operandSize returns the operand size in bytes selected by the prefixes
+func (instruction *amd64Instruction) operandSize() int {
+	switch {
+	case instruction.rex&0x08 != 0:
+		return 8
+	case instruction.operand16:
+		return 2
+	}
+
+	return 4
+}
+
+// rexBit returns 8 when the REX prefix bit is set, extending a register number
+func (instruction *amd64Instruction) rexBit(bit byte) int {
+	if instruction.rex&bit != 0 {
+		return 8
+	}
+
+	return 0
+}
+
+// register returns the register selected by the reg field of the ModRM byte
+func (instruction *amd64Instruction) register(size int) string {
+	return amd64Registers[size][instruction.reg]
+}
+
+// modrm decodes the ModRM byte and the SIB byte and displacement following it. It returns the register or memory
+// operand it selects, with registers and memory of size bytes
+func (instruction *amd64Instruction) modrm(size int) string {
+	return instruction.decodeModrm(size, func(register int) string {
+		return amd64Registers[size][register]
+	})
+}
+
+// modrmXmm is modrm with xmm registers as register operand
+func (instruction *amd64Instruction) modrmXmm(size int) string {
+	return instruction.decodeModrm(size, func(register int) string {
+		return fmt.Sprintf("xmm%d", register)
+	})
+}
+
+func (instruction *amd64Instruction) decodeModrm(size int, register func(register int) string) string {
+	modrm := instruction.byte()
+	instruction.mod = modrm >> 6
+	instruction.reg = int(modrm>>3&0x07) | instruction.rexBit(0x04)
+	instruction.rm = modrm & 0x07
+
+	if instruction.mod == 3 {
+		return register(int(instruction.rm) | instruction.rexBit(0x01))
+	}
+
+	pointer := amd64PointerSizes[size]
+
+	// rip-relative addressing, the displacement is relative to the end of the instruction, which ends with it unless
+	// an immediate follows, never the case in generated code
+	if instruction.mod == 0 && instruction.rm == 5 {
+		displacement := instruction.signed(4)
+		target := int64(instruction.address+instruction.position) + displacement
+
+		return fmt.Sprintf("%s[rip%s]  # %#x", pointer, formatDisplacement(displacement), target)
+	}
+
+	var address string
+
+	if instruction.rm == 4 {
+		sib := instruction.byte()
+		base := int(sib&0x07) | instruction.rexBit(0x01)
+		index := int(sib>>3&0x07) | instruction.rexBit(0x02)
+
+		if sib&0x07 == 5 && instruction.mod == 0 {
+			// no base register, only a 32-bit displacement
+			instruction.mod = 2
+		} else {
+			address = amd64Registers[8][base]
+		}
+
+		// index 4 without the REX extension means there is no index register
+		if index != 4 {
+			if address != "" {
+				address += "+"
+			}
+
+			address += fmt.Sprintf("%s*%d", amd64Registers[8][index], 1<<(sib>>6))
+		}
+	} else {
+		address = amd64Registers[8][int(instruction.rm)|instruction.rexBit(0x01)]
+	}
+
+	switch instruction.mod {
+	case 1:
+		address += formatDisplacement(instruction.signed(1))
+	case 2:
+		address += formatDisplacement(instruction.signed(4))
+	}
+
+	return pointer + "[" + address + "]"
+}
+
+// immediateSizeOf returns the size of an immediate for an operand size, 64-bit operands use sign extended 32 bits
+func immediateSizeOf(size int) int {
+	if size == 8 {
+		return 4
+	}
+
+	return size
+}
+
+func formatDisplacement(displacement int64) string {
+	if displacement < 0 {
+		return fmt.Sprintf("-%#x", -displacement)
+	}
+
+	return fmt.Sprintf("+%#x", displacement)
+}
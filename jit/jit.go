@@ -1,27 +1,110 @@
 package jit
 
-import "gobf/instructions"
+import (
+	"errors"
+	"gobf/execution"
+	"gobf/instructions"
+)
+
+// ErrUnsupportedPlatform is returned when no JIT backend exists for the current platform
+var ErrUnsupportedPlatform = errors.New("jit is not supported on this platform")
+
+// Exit codes returned by the generated code to our Go program
+const (
+	exitCodeSuccess = iota
+	exitCodeOutOfBounds
+	// the output buffer is full, or output is unbuffered
+	exitCodeFlush
+	// the Read instruction needs a byte of input
+	exitCodeRead
+	// the steps handed out by Go have been used up, Go checks the context and the step limit before continuing
+	exitCodeYield
+	// the WriteString instruction needs its text written, the instruction index is returned with it
+	exitCodeWriteString
+)
 
 type Jit struct {
-	memorySize uint
+	options    execution.Options
 	code       []byte
 	codeBlocks []CodeBlock
+	// epilogue is the offset of the code returning to Go after the last instruction, followed by the stubs
+	epilogue int
+	stubs    []stubCode
+	// decode disassembles the instruction at the start of code for the architecture the code was generated for
+	decode decoder
 }
 
 type CodeBlock struct {
 	instruction instructions.Instruction
 	offset      int
-	link        *CodeBlock
+	// end is the offset right after the last byte of the block, jump blocks end with their jump instruction
+	end  int
+	link *CodeBlock
+}
+
+// exitStub is a piece of code placed after the program, which is jumped to from offset to return to our Go program
+type exitStub struct {
+	offset      int
+	instruction int
+	// pointerOffset is added to the address counter to get the pointer which is out of bounds
+	pointerOffset int
+}
+
+// yieldStub is a piece of code placed after the program, which is jumped to from offset when the step counter reaches
+// 0. It returns to Go, and the program continues at resume once Go calls the generated code again
+type yieldStub struct {
+	offset      int
+	instruction int
+	resume      int
+}
+
+// stubCode is the generated code of an exit or yield stub, which returns to Go on behalf of an instruction
+type stubCode struct {
+	description string
+	instruction int
+	offset      int
+	end         int
 }
 
-func NewJit(memorySize uint) *Jit {
+func NewJit(options execution.Options) *Jit {
 	return &Jit{
-		memorySize,
-		make([]byte, 0),
-		make([]CodeBlock, 0),
+		options:    options,
+		code:       make([]byte, 0),
+		codeBlocks: make([]CodeBlock, 0),
 	}
 }
 
 func (jit *Jit) GeneratedCode() []byte {
 	return jit.code
 }
+
+// exitError converts the values returned by the generated code into an error
+func (jit *Jit) exitError(exitCode uint64, instruction uint64, pointer uint64) error {
+	switch exitCode {
+	case exitCodeSuccess:
+		return nil
+	case exitCodeOutOfBounds:
+		return &execution.BoundsError{
+			Instruction: int(instruction),
+			// The address counter can be 32 or 64 bits wide depending on the architecture, but memory never
+			// exceeds 31 bits, so a 32-bit conversion gives the correct sign for both
+			Pointer: int(int32(pointer)),
+			Span:    jit.codeBlocks[instruction].instruction.Span,
+		}
+	}
+
+	return errors.New("unknown exit code returned by generated code")
+}
+
+// checkOptions makes sure the options are supported, and the memory size can be encoded as an immediate by the bounds check
+func (jit *Jit) checkOptions() error {
+	if err := jit.options.Validate(); err != nil {
+		return err
+	}
+
+	if jit.options.BoundsCheck && jit.options.MemorySize >= 1<<31 {
+		return errors.New("memory size too large for bounds checking")
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+//go:build (darwin && arm64) || (linux && (amd64 || arm64))
+
+package jit
+
+import (
+	"context"
+	"errors"
+	"gobf/execution"
+	"io"
+	"runtime/debug"
+	"syscall"
+	"unsafe"
+)
+
+// guardPages is the number of inaccessible pages placed before and after program memory, any access to them is
+// reported as a TapeError instead of corrupting or crashing the process
+const guardPages = 16
+
+// Run executes the generated code, reading input from input and writing output to output, until the program ends,
+// ctx is done or the step limit is exceeded
+func (jit *Jit) Run(ctx context.Context, input io.Reader, output io.Writer) (err error) {
+	if err := ctx.Err(); err != nil {
+		return execution.Canceled(err)
+	}
+
+	if jit.options.MemorySize == 0 {
+		return errors.New("failed to map program memory: memory size must be larger than 0")
+	}
+
+	pageSize := syscall.Getpagesize()
+	guardSize := guardPages * pageSize
+
+	// Program memory is rounded up to whole pages, so overflows are detected once the last page is left
+	memorySize := (int(jit.options.MemorySize)*jit.options.CellBytes() + pageSize - 1) / pageSize * pageSize
+
+	// Reserve program memory including guard pages, and only make program memory itself accessible
+	reservedMemory, err := syscall.Mmap(-1, 0, guardSize+memorySize+guardSize, syscall.PROT_NONE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return errors.New("failed to map program memory: " + err.Error())
+	}
+	defer syscall.Munmap(reservedMemory)
+
+	programMemory := reservedMemory[guardSize : guardSize+memorySize]
+	if err := syscall.Mprotect(programMemory, syscall.PROT_READ|syscall.PROT_WRITE); err != nil {
+		return errors.New("failed to make program memory accessible: " + err.Error())
+	}
+
+	// Allocate executable memory
+	executableMemory, err := syscall.Mmap(-1, 0, len(jit.code), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return errors.New("failed to map executable memory: " + err.Error())
+	}
+	defer syscall.Munmap(executableMemory)
+
+	// Copy JIT instructions to executable memory
+	copy(executableMemory, jit.code)
+
+	// Change permissions to executable
+	if err := syscall.Mprotect(executableMemory, syscall.PROT_EXEC); err != nil {
+		return errors.New("failed to make executable memory executable: " + err.Error())
+	}
+
+	// Cast allocated memory regions to function pointers
+	executableMemoryPointer := &executableMemory
+	programMemoryPointer := unsafe.Pointer(&programMemory[0])
+
+	budget := &stepBudget{ctx: ctx, limit: jit.options.MaxSteps}
+	state := &state{steps: budget.next()}
+
+	// Write whatever is left in the output buffer once the program has stopped, also when it failed
+	defer func() {
+		if flushErr := state.flush(output); flushErr != nil && err == nil {
+			err = flushErr
+		}
+	}()
+
+	// Turn faults in the generated code into panics, so accesses to the guard pages can be recovered from
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = guardPageError(recovered, reservedMemory, guardSize, jit.options.CellBytes())
+		}
+	}()
+
+	// Define JIT call function
+	f := *(*func(programMemory unsafe.Pointer, state unsafe.Pointer) (exitCode uint64, instruction uint64, pointer uint64))(unsafe.Pointer(&executableMemoryPointer))
+
+	// Execute it, until it stops for any other reason than input or output
+	for {
+		exitCode, instruction, pointer := f(programMemoryPointer, unsafe.Pointer(state))
+
+		switch exitCode {
+		case exitCodeFlush:
+			if err := state.flush(output); err != nil {
+				return err
+			}
+		case exitCodeRead:
+			// make sure everything written so far is visible before waiting for input
+			if err := state.flush(output); err != nil {
+				return err
+			}
+
+			if err := state.readCell(input, programMemory, jit.options.CellBytes()); err != nil {
+				return err
+			}
+		case exitCodeWriteString:
+			if err := state.writeString(output, jit.codeBlocks[instruction].instruction.Text); err != nil {
+				return err
+			}
+
+			if jit.options.Unbuffered {
+				if err := state.flush(output); err != nil {
+					return err
+				}
+			}
+		case exitCodeYield:
+			if state.steps, err = budget.resume(); err != nil {
+				return err
+			}
+		default:
+			return jit.exitError(exitCode, instruction, pointer)
+		}
+	}
+}
+
+// guardPageError converts a fault in one of the guard pages to a TapeError, any other panic is passed on
+func guardPageError(recovered any, reservedMemory []byte, guardSize int, cellBytes int) error {
+	fault, ok := recovered.(interface{ Addr() uintptr })
+	if !ok {
+		panic(recovered)
+	}
+
+	start := uintptr(unsafe.Pointer(&reservedMemory[0]))
+	if fault.Addr() < start || fault.Addr() >= start+uintptr(len(reservedMemory)) {
+		panic(recovered)
+	}
+
+	offset := int(fault.Addr()-start) - guardSize
+
+	// Round down to the cell containing the faulting address, also for negative offsets
+	pointer := offset / cellBytes
+	if offset < 0 && offset%cellBytes != 0 {
+		pointer--
+	}
+
+	return &execution.TapeError{
+		Overflow: offset >= 0,
+		Pointer:  pointer,
+	}
+}
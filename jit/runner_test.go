@@ -0,0 +1,323 @@
+//go:build (darwin && arm64) || (linux && (amd64 || arm64))
+
+package jit
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"gobf/execution"
+	"gobf/instructions"
+	"gobf/parser"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJit_RunCellSizes(t *testing.T) {
+	var tests = []struct {
+		name      string
+		cellSize  uint
+		increment int
+		wraps     bool
+	}{
+		{"8-bit wraps at 256", 8, 256, true},
+		{"16-bit holds 256", 16, 256, false},
+		{"16-bit wraps at 65536", 16, 65536, true},
+		{"32-bit holds 65536", 32, 65536, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// a nonzero cell enters the loop, which moves the pointer out of bounds
+			jit := NewJit(execution.Options{MemorySize: 5, CellSize: test.cellSize, BoundsCheck: true})
+			assert.NoError(t, jit.Compile([]instructions.Instruction{
+				{Name: instructions.Increment, Value: test.increment},
+				{Name: instructions.JumpIfZero, Value: 3},
+				{Name: instructions.MoveLeft, Value: 1},
+				{Name: instructions.JumpUnlessZero, Value: 1},
+			}))
+
+			err := jit.Run(context.Background(), strings.NewReader(""), &bytes.Buffer{})
+			if test.wraps {
+				assert.NoError(t, err)
+				return
+			}
+
+			var boundsError *execution.BoundsError
+			assert.ErrorAs(t, err, &boundsError)
+		})
+	}
+}
+
+func TestJit_Run(t *testing.T) {
+	var tests = []struct {
+		name    string
+		input   string
+		stdin   string
+		output  string
+		options execution.Options
+	}{
+		{"write", "++++++++[>++++++++<-]>+.", "", "A", execution.Options{}},
+		{"read", ",+.,+.", "ab", "bc", execution.Options{}},
+		{"read eof leaves cell unchanged", "+++,.", "", "\x03", execution.Options{}},
+		{"read eof minus-one", "+++,.", "", "\xff", execution.Options{EOF: execution.EOFMinusOne}},
+		{"read wide cell", "-,+.", "a", "b", execution.Options{CellSize: 32}},
+		{"flush full buffer", strings.Repeat("+", 'A') + ">" + strings.Repeat("+", 80) + "[>" + strings.Repeat("+", 80) + "[<<.>>-]<-]", "", strings.Repeat("A", 80*80), execution.Options{CellSize: 16}},
+		{"unbuffered", "+.+.", "", "\x01\x02", execution.Options{Unbuffered: true}},
+		{"multiply", ">+++++[-<+++++++++++++>>++<]<.>>.", "", "A\x0a", execution.Options{}},
+		{"multiply negative", ">+++[-<-->]<.", "", "\xfa", execution.Options{CellSize: 32}},
+		{"multiply wide cell", "++[->" + strings.Repeat("+", 128) + "<]>[>" + strings.Repeat("+", 'Y') + ".<[-]]", "", "Y", execution.Options{CellSize: 16}},
+		{"offsets", ">,>,<<+++>>>++[<<<+.>.>.<<.>>>-]", "ab", "\x04ab\x04\x05ab\x05", execution.Options{CellSize: 16}},
+		{"write string", "+++++[>+++++++++++++<-]>.,.", "b", "Ab", execution.Options{}},
+		{"write string unbuffered", "+++++[>+++++++++++++<-]>.,.", "b", "Ab", execution.Options{Unbuffered: true}},
+		{"write long string", "+" + strings.Repeat(".", 5000) + ",.", "b", strings.Repeat("\x01", 5000) + "b", execution.Options{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			instructionParser := parser.NewParser()
+			parsedInstructions, err := instructionParser.Parse(test.input)
+			assert.NoError(t, err)
+
+			output := &bytes.Buffer{}
+
+			test.options.MemorySize = 100
+			jit := NewJit(test.options)
+			assert.NoError(t, jit.Compile(instructions.OptimizeInstructions(parsedInstructions)))
+			assert.NoError(t, jit.Run(context.Background(), strings.NewReader(test.stdin), output))
+
+			assert.Equal(t, test.output, output.String())
+		})
+	}
+}
+
+func TestJit_RunOutputBeforeError(t *testing.T) {
+	jit := NewJit(execution.Options{MemorySize: 100})
+	assert.NoError(t, jit.Compile([]instructions.Instruction{
+		{Name: instructions.Increment, Value: 'A'},
+		{Name: instructions.Write, Value: 1},
+		{Name: instructions.MoveLeft, Value: 10},
+		{Name: instructions.Write, Value: 1},
+	}))
+
+	output := &bytes.Buffer{}
+	err := jit.Run(context.Background(), strings.NewReader(""), output)
+
+	var tapeError *execution.TapeError
+	assert.ErrorAs(t, err, &tapeError)
+	assert.Equal(t, "A", output.String())
+}
+
+func TestJit_RunBoundsCheck(t *testing.T) {
+	var tests = []struct {
+		name     string
+		input    []instructions.Instruction
+		expected string
+	}{
+		{
+			"underflow",
+			[]instructions.Instruction{
+				{Name: instructions.Increment, Value: 1},
+				{Name: instructions.MoveLeft, Value: 1},
+			},
+			"pointer out of bounds at instruction 1: -1",
+		},
+		{
+			"overflow",
+			[]instructions.Instruction{
+				{Name: instructions.MoveRight, Value: 4},
+				{Name: instructions.Clear},
+				{Name: instructions.MoveRight, Value: 1},
+			},
+			"pointer out of bounds at instruction 2: 5",
+		},
+		{
+			"in bounds",
+			[]instructions.Instruction{
+				{Name: instructions.MoveRight, Value: 4},
+				{Name: instructions.MoveLeft, Value: 4},
+			},
+			"",
+		},
+		{
+			"multiply",
+			[]instructions.Instruction{
+				{Name: instructions.MoveRight, Value: 4},
+				{Name: instructions.Increment, Value: 1},
+				{Name: instructions.MulAdd, Value: 1, Offset: -2},
+				{Name: instructions.MulAdd, Value: 1, Offset: 1},
+			},
+			"pointer out of bounds at instruction 3: 5",
+		},
+		{
+			"scan",
+			[]instructions.Instruction{
+				{Name: instructions.Increment, Value: 1},
+				{Name: instructions.MoveRight, Value: 2},
+				{Name: instructions.Increment, Value: 1},
+				{Name: instructions.ScanLeft, Value: 2},
+			},
+			"pointer out of bounds at instruction 3: -2",
+		},
+		{
+			"offset",
+			[]instructions.Instruction{
+				{Name: instructions.MoveRight, Value: 2},
+				{Name: instructions.Increment, Value: 1, Offset: 2},
+				{Name: instructions.Increment, Value: 1, Offset: 3},
+			},
+			"pointer out of bounds at instruction 2: 5",
+		},
+		{
+			"offset read",
+			[]instructions.Instruction{
+				{Name: instructions.MoveRight, Value: 2},
+				{Name: instructions.Read, Value: 1, Offset: -3},
+			},
+			"pointer out of bounds at instruction 1: -1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jit := NewJit(execution.Options{MemorySize: 5, BoundsCheck: true})
+			assert.NoError(t, jit.Compile(test.input))
+
+			err := jit.Run(context.Background(), strings.NewReader(""), &bytes.Buffer{})
+			if test.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var boundsError *execution.BoundsError
+			assert.ErrorAs(t, err, &boundsError)
+			assert.EqualError(t, err, test.expected)
+		})
+	}
+}
+
+func TestJit_RunGuardPages(t *testing.T) {
+	var tests = []struct {
+		name     string
+		input    []instructions.Instruction
+		expected execution.TapeError
+	}{
+		{
+			"underflow",
+			[]instructions.Instruction{
+				{Name: instructions.MoveLeft, Value: 2},
+				{Name: instructions.Increment, Value: 1},
+			},
+			execution.TapeError{Overflow: false, Pointer: -2},
+		},
+		{
+			"overflow",
+			[]instructions.Instruction{
+				{Name: instructions.MoveRight, Value: 1 << 20},
+				{Name: instructions.Clear},
+			},
+			execution.TapeError{Overflow: true, Pointer: 1 << 20},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jit := NewJit(execution.Options{MemorySize: 1 << 20})
+			assert.NoError(t, jit.Compile(test.input))
+
+			err := jit.Run(context.Background(), strings.NewReader(""), &bytes.Buffer{})
+
+			var tapeError *execution.TapeError
+			assert.ErrorAs(t, err, &tapeError)
+			assert.Equal(t, test.expected, *tapeError)
+		})
+	}
+}
+
+func TestJit_RunStepLimit(t *testing.T) {
+	// 255 iterations of the outer loop, each running the middle loop 255 times, which run the inner loop 255 times
+	const program = "-[>-[>-[-]<-]<-]"
+	const steps = 255*255*255 + 255*255 + 255
+
+	var tests = []struct {
+		name     string
+		options  execution.Options
+		expected error
+	}{
+		{"exact", execution.Options{MaxSteps: steps}, nil},
+		{"exceeded", execution.Options{MaxSteps: steps - 1}, execution.ErrStepLimit},
+		{"unlimited", execution.Options{}, nil},
+		// returning to Go for every write must keep the step counter
+		{"unbuffered", execution.Options{MaxSteps: 3, Unbuffered: true}, execution.ErrStepLimit},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input := program
+			if test.options.Unbuffered {
+				input = "++++[.-]"
+			}
+
+			instructionParser := parser.NewParser()
+			parsedInstructions, err := instructionParser.Parse(input)
+			assert.NoError(t, err)
+
+			test.options.MemorySize = 100
+			jit := NewJit(test.options)
+			assert.NoError(t, jit.Compile(parsedInstructions))
+
+			output := &bytes.Buffer{}
+			err = jit.Run(context.Background(), strings.NewReader(""), output)
+			if test.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, test.expected)
+		})
+	}
+}
+
+func TestJit_RunCanceled(t *testing.T) {
+	jit := NewJit(execution.Options{MemorySize: 100})
+	assert.NoError(t, jit.Compile([]instructions.Instruction{
+		{Name: instructions.Increment, Value: 1},
+		{Name: instructions.JumpIfZero, Value: 2},
+		{Name: instructions.JumpUnlessZero, Value: 1},
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := jit.Run(ctx, strings.NewReader(""), &bytes.Buffer{})
+	assert.ErrorIs(t, err, execution.ErrCanceled)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestJit_RunGarbageCollection(t *testing.T) {
+	jit := NewJit(execution.Options{MemorySize: 100})
+	assert.NoError(t, jit.Compile([]instructions.Instruction{
+		{Name: instructions.Increment, Value: 1},
+		{Name: instructions.JumpIfZero, Value: 2},
+		{Name: instructions.JumpUnlessZero, Value: 1},
+	}))
+
+	// the program never ends, and its context is never done
+	go jit.Run(context.Background(), strings.NewReader(""), &bytes.Buffer{})
+	time.Sleep(10 * time.Millisecond)
+
+	// stopping the world waits for the generated code to return to Go
+	collected := make(chan struct{})
+	go func() {
+		runtime.GC()
+		close(collected)
+	}()
+
+	select {
+	case <-collected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("garbage collection is blocked by the running program")
+	}
+}
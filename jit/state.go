@@ -0,0 +1,137 @@
+package jit
+
+import (
+	"context"
+	"errors"
+	"gobf/execution"
+	"io"
+)
+
+// outputBufferSize is the number of bytes written by the program before the output buffer is flushed, the size minus
+// one must fit in the 12-bit immediate of an AArch64 compare instruction
+const outputBufferSize = 4096
+
+// state is shared between Go and the generated code. The generated code doesn't make any syscalls, instead it saves
+// its address counter and the address to resume at, and returns to Go with an exit code asking for input or for the
+// output buffer to be flushed. Go handles the request and calls the generated code again, which continues at resume.
+// The generated code expects the fields at offsets 0, 8, 16, 24, 32 and 40
+type state struct {
+	// resume is the address the generated code continues at when it is called, 0 starts the program
+	resume uint64
+	// pointer is the address counter, saved when returning to Go
+	pointer uint64
+	// read is the number of bytes read by Go for the Read instruction, 0 on EOF
+	read uint64
+	// length is the number of bytes in output
+	length uint64
+	// steps is the step counter, which the generated code decrements before every step and returns to Go with
+	// exitCodeYield when it reaches 0. Saved when returning to Go
+	steps  uint64
+	output [outputBufferSize]byte
+}
+
+// flush writes the buffered output and empties the buffer
+func (state *state) flush(output io.Writer) error {
+	if state.length == 0 {
+		return nil
+	}
+
+	length := state.length
+	state.length = 0
+
+	if _, err := output.Write(state.output[:length]); err != nil {
+		return errors.New("failed to write output: " + err.Error())
+	}
+
+	return nil
+}
+
+// writeString adds text to the output buffer, flushing the buffer first when text doesn't fit. The buffer is never left
+// full, since the generated code only flushes it after adding a byte. Text which doesn't fit in the buffer at all is
+// written right away
+func (state *state) writeString(output io.Writer, text string) error {
+	if int(state.length)+len(text) >= outputBufferSize {
+		if err := state.flush(output); err != nil {
+			return err
+		}
+
+		if len(text) >= outputBufferSize {
+			if _, err := io.WriteString(output, text); err != nil {
+				return errors.New("failed to write output: " + err.Error())
+			}
+
+			return nil
+		}
+	}
+
+	state.length += uint64(copy(state.output[state.length:], text))
+
+	return nil
+}
+
+// readCell reads a single byte of input into the lowest byte of the current cell, on EOF the cell is left alone so
+// the generated code can handle it
+func (state *state) readCell(input io.Reader, memory []byte, cellBytes int) error {
+	state.read = 0
+
+	// Without bounds checking the pointer can be anywhere, the generated code would have faulted on a guard page
+	pointer := int(int64(state.pointer))
+	if pointer < 0 || pointer*cellBytes >= len(memory) {
+		return &execution.TapeError{Overflow: pointer >= 0, Pointer: pointer}
+	}
+
+	// Cells are little-endian, so the lowest byte is the first one
+	if _, err := io.ReadFull(input, memory[pointer*cellBytes:][:1]); err != nil {
+		if err == io.EOF {
+			return nil
+		}
+
+		return errors.New("failed to read input: " + err.Error())
+	}
+
+	state.read = 1
+
+	return nil
+}
+
+// stepBudget hands out steps to the generated code, in parts small enough to check the context regularly
+type stepBudget struct {
+	ctx context.Context
+	// limit is the maximum number of steps, 0 doesn't limit them
+	limit uint64
+	// used is the number of steps handed out so far
+	used uint64
+}
+
+// next hands out the steps until the generated code should return to Go again, and returns the matching value of the
+// step counter. The step which brings the counter to 0 isn't executed until Go continues, so the counter is one more
+// than the number of steps
+func (budget *stepBudget) next() uint64 {
+	// the runtime can't preempt the generated code, it returns to Go regularly even when the context is never done, so
+	// a garbage collection doesn't have to wait until the program ends
+	steps := uint64(execution.CancelCheckInterval)
+
+	if budget.limit != 0 && budget.limit-budget.used < steps {
+		steps = budget.limit - budget.used
+	}
+
+	budget.used += steps
+
+	return steps + 1
+}
+
+// resume is called when the generated code has used up its steps, and returns the step counter to continue with, or
+// an error when the context is done or no steps are left for the step waiting to be executed
+func (budget *stepBudget) resume() (uint64, error) {
+	if err := budget.ctx.Err(); err != nil {
+		return 0, execution.Canceled(err)
+	}
+
+	if budget.limit != 0 && budget.used == budget.limit {
+		return 0, execution.ErrStepLimit
+	}
+
+	budget.used++
+
+	return budget.next(), nil
+}
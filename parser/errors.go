@@ -0,0 +1,17 @@
+package parser
+
+import "fmt"
+
+// ParseError describes why the input could not be parsed, and where in the input the problem was found
+type ParseError struct {
+	Message string
+	// Line and Column are 1-based, Column counts characters rather than bytes
+	Line   int
+	Column int
+	// Offset is the 0-based byte offset into the input
+	Offset int
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("%s at line %d, column %d", err.Message, err.Line, err.Column)
+}
@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"errors"
 	"gobf/instructions"
 )
 
@@ -14,7 +13,9 @@ func NewParser() Parser {
 func (parser *Parser) Parse(input string) ([]instructions.Instruction, error) {
 	var depth = 0
 	var depthMap = map[int]int{}
+	var depthPositions = map[int]instructions.Position{}
 	var counter = 0
+	var current = instructions.Position{Line: 1}
 
 	var validInstructions = 0
 	for _, character := range input {
@@ -27,7 +28,15 @@ func (parser *Parser) Parse(input string) ([]instructions.Instruction, error) {
 
 	parsedInstructions := make([]instructions.Instruction, 0, validInstructions)
 
-	for _, character := range input {
+	for offset, character := range input {
+		current.Offset = offset
+		current.Column++
+
+		if character == '\n' {
+			current.Line++
+			current.Column = 0
+		}
+
 		instructionName := parser.instructionTypeFromCharacter(character)
 
 		if instructionName == instructions.Unknown {
@@ -38,17 +47,19 @@ func (parser *Parser) Parse(input string) ([]instructions.Instruction, error) {
 		if instructionName == instructions.JumpIfZero {
 			depth++
 			depthMap[depth] = counter
+			depthPositions[depth] = current
 
 			instructionValue = 0
 		}
 
 		if instructionName == instructions.JumpUnlessZero {
 			if depth == 0 {
-				return nil, errors.New("no matching '[' found")
+				return nil, newParseError("no matching '[' found", current)
 			}
 
 			instructionValue = depthMap[depth]
 			delete(depthMap, depth)
+			delete(depthPositions, depth)
 
 			depth--
 			parsedInstructions[instructionValue].Value = counter
@@ -57,6 +68,7 @@ func (parser *Parser) Parse(input string) ([]instructions.Instruction, error) {
 		instruction := instructions.Instruction{
 			Name:  instructionName,
 			Value: instructionValue,
+			Span:  instructions.NewSpan(current),
 		}
 
 		parsedInstructions = append(parsedInstructions, instruction)
@@ -64,12 +76,22 @@ func (parser *Parser) Parse(input string) ([]instructions.Instruction, error) {
 	}
 
 	if len(depthMap) != 0 {
-		return nil, errors.New("no matching ']' found")
+		// Report the innermost unmatched opening bracket, all outer brackets are unmatched because of it
+		return nil, newParseError("no matching ']' found", depthPositions[depth])
 	}
 
 	return parsedInstructions, nil
 }
 
+func newParseError(message string, position instructions.Position) *ParseError {
+	return &ParseError{
+		Message: message,
+		Line:    position.Line,
+		Column:  position.Column,
+		Offset:  position.Offset,
+	}
+}
+
 func (parser *Parser) instructionTypeFromCharacter(character rune) instructions.InstructionType {
 	switch character {
 	case '>':
@@ -55,10 +55,12 @@ func TestParser_ParseMultiple(t *testing.T) {
 				{
 					Name:  instructions.Increment,
 					Value: 1,
+					Span:  columnSpan(0),
 				},
 				{
 					Name:  instructions.Decrement,
 					Value: 1,
+					Span:  columnSpan(1),
 				},
 			},
 		},
@@ -68,18 +70,22 @@ func TestParser_ParseMultiple(t *testing.T) {
 				{
 					Name:  instructions.MoveRight,
 					Value: 1,
+					Span:  columnSpan(0),
 				},
 				{
 					Name:  instructions.MoveRight,
 					Value: 1,
+					Span:  columnSpan(1),
 				},
 				{
 					Name:  instructions.MoveLeft,
 					Value: 1,
+					Span:  columnSpan(2),
 				},
 				{
 					Name:  instructions.MoveRight,
 					Value: 1,
+					Span:  columnSpan(3),
 				},
 			},
 		},
@@ -89,26 +95,32 @@ func TestParser_ParseMultiple(t *testing.T) {
 				{
 					Name:  instructions.JumpIfZero,
 					Value: 3,
+					Span:  columnSpan(0),
 				},
 				{
 					Name:  instructions.JumpIfZero,
 					Value: 2,
+					Span:  columnSpan(1),
 				},
 				{
 					Name:  instructions.JumpUnlessZero,
 					Value: 1,
+					Span:  columnSpan(2),
 				},
 				{
 					Name:  instructions.JumpUnlessZero,
 					Value: 0,
+					Span:  columnSpan(3),
 				},
 				{
 					Name:  instructions.JumpIfZero,
 					Value: 5,
+					Span:  columnSpan(4),
 				},
 				{
 					Name:  instructions.JumpUnlessZero,
 					Value: 4,
+					Span:  columnSpan(5),
 				},
 			},
 		},
@@ -126,6 +138,25 @@ func TestParser_ParseMultiple(t *testing.T) {
 	}
 }
 
+func TestParser_ParseSpans(t *testing.T) {
+	parser := NewParser()
+	instructions, err := parser.Parse("+ x\n\t>é.")
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"1:1", "2:2", "2:4"}, []string{
+		instructions[0].Span.String(),
+		instructions[1].Span.String(),
+		instructions[2].Span.String(),
+	})
+	assert.Equal(t, 8, instructions[2].Span.Start.Offset)
+}
+
+// columnSpan returns the span of a single character instruction on the first line
+func columnSpan(offset int) instructions.Span {
+	return instructions.NewSpan(instructions.Position{Line: 1, Column: offset + 1, Offset: offset})
+}
+
 func TestParser_MatchJumpGroups(t *testing.T) {
 	ifZero := instructions.JumpIfZero
 	unlessZero := instructions.JumpUnlessZero
@@ -144,13 +175,39 @@ func TestParser_MatchJumpGroups(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			instructions, err := parser.Parse(test.input)
 
-			assert.Equal(t, fmt.Sprintf("no matching '%s' found", test.expectedMissing), err.Error())
+			assert.Equal(t, fmt.Sprintf("no matching '%s' found at line 1, column 1", test.expectedMissing), err.Error())
 
 			assert.Empty(t, instructions)
 		})
 	}
 }
 
+func TestParser_ParseErrorPosition(t *testing.T) {
+	var tests = []struct {
+		name     string
+		input    string
+		expected ParseError
+	}{
+		{"unmatched close", "+\n+ ]", ParseError{Message: "no matching '[' found", Line: 2, Column: 3, Offset: 4}},
+		{"unmatched close after loop", "[-]]", ParseError{Message: "no matching '[' found", Line: 1, Column: 4, Offset: 3}},
+		{"unmatched open", "+\n\n  [[-]", ParseError{Message: "no matching ']' found", Line: 3, Column: 3, Offset: 5}},
+		{"innermost unmatched open", "[\n[", ParseError{Message: "no matching ']' found", Line: 2, Column: 1, Offset: 2}},
+		{"multibyte characters", "é ]", ParseError{Message: "no matching '[' found", Line: 1, Column: 3, Offset: 3}},
+	}
+
+	for _, test := range tests {
+		parser := NewParser()
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parser.Parse(test.input)
+
+			var parseError *ParseError
+			assert.ErrorAs(t, err, &parseError)
+
+			assert.Equal(t, test.expected, *parseError)
+		})
+	}
+}
+
 func FuzzParser_Parse(f *testing.F) {
 	parser := NewParser()
 	f.Fuzz(func(t *testing.T, input string) {
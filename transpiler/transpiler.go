@@ -0,0 +1,125 @@
+package transpiler
+
+import (
+	"fmt"
+	"gobf/execution"
+	"gobf/instructions"
+	"strings"
+)
+
+// StepLimitExitCode is the exit code of the transpiled program when it exceeds the step limit
+const StepLimitExitCode = 3
+
+// TranspileToC converts the instructions into a standalone C program, which can be compiled by any C compiler
+func TranspileToC(parsedInstructions []instructions.Instruction, options execution.Options) string {
+	var source strings.Builder
+	depth := 1
+
+	// Cells are unsigned integers of the configured size, so they wrap around like in the other engines
+	cellType := fmt.Sprintf("uint%d_t", options.CellBytes()*8)
+
+	source.WriteString("#include <stdint.h>\n")
+	source.WriteString("#include <stdio.h>\n\n")
+	source.WriteString(fmt.Sprintf("static %s memory[%d];\n\n", cellType, options.MemorySize))
+	source.WriteString("int main(void) {\n")
+	source.WriteString(fmt.Sprintf("\t%s *pointer = memory;\n", cellType))
+	source.WriteString("\tint character;\n")
+
+	if options.MaxSteps != 0 {
+		source.WriteString(fmt.Sprintf("\tuint64_t steps = %dULL;\n", options.MaxSteps))
+	}
+
+	source.WriteString("\n")
+
+	if options.Unbuffered {
+		source.WriteString("\tsetvbuf(stdout, NULL, _IONBF, 0);\n\n")
+	}
+
+	for _, instruction := range parsedInstructions {
+		if instruction.Name == instructions.JumpUnlessZero {
+			// Every iteration of a loop is a step
+			if options.MaxSteps != 0 {
+				source.WriteString(strings.Repeat("\t", depth))
+				source.WriteString(fmt.Sprintf("if (steps-- == 0) return %d;\n", StepLimitExitCode))
+			}
+
+			depth--
+		}
+
+		source.WriteString(strings.Repeat("\t", depth))
+
+		switch instruction.Name {
+		case instructions.MoveRight:
+			source.WriteString(fmt.Sprintf("pointer += %d;\n", instruction.Value))
+		case instructions.MoveLeft:
+			source.WriteString(fmt.Sprintf("pointer -= %d;\n", instruction.Value))
+		case instructions.Increment:
+			source.WriteString(fmt.Sprintf("%s += %d;\n", cell(instruction.Offset), instruction.Value))
+		case instructions.Decrement:
+			source.WriteString(fmt.Sprintf("%s -= %d;\n", cell(instruction.Offset), instruction.Value))
+		case instructions.Write:
+			source.WriteString(fmt.Sprintf("putchar(%s);\n", cell(instruction.Offset)))
+		case instructions.Read:
+			// Flush pending output first so prompts are visible
+			source.WriteString(fmt.Sprintf("fflush(stdout); if ((character = getchar()) != EOF) %s = character;", cell(instruction.Offset)))
+			if options.EOF != execution.EOFUnchanged {
+				source.WriteString(fmt.Sprintf(" else %s = %d;", cell(instruction.Offset), options.EOFValue()))
+			}
+			source.WriteString("\n")
+		case instructions.JumpIfZero:
+			source.WriteString("while (*pointer) {\n")
+			depth++
+		case instructions.JumpUnlessZero:
+			source.WriteString("}\n")
+		case instructions.Clear:
+			source.WriteString(fmt.Sprintf("%s = 0;\n", cell(instruction.Offset)))
+		case instructions.MulAdd:
+			source.WriteString(fmt.Sprintf("pointer[%d] += *pointer * %d;\n", instruction.Offset, instruction.Value))
+		case instructions.Set:
+			source.WriteString(fmt.Sprintf("%s = %d;\n", cell(instruction.Offset), instruction.Value))
+		case instructions.WriteString:
+			source.WriteString(fmt.Sprintf("fwrite(%s, 1, %d, stdout);\n", stringLiteral(instruction.Text), len(instruction.Text)))
+		case instructions.ScanRight:
+			source.WriteString(fmt.Sprintf("while (*pointer) pointer += %d;\n", instruction.Value))
+		case instructions.ScanLeft:
+			source.WriteString(fmt.Sprintf("while (*pointer) pointer -= %d;\n", instruction.Value))
+		}
+	}
+
+	source.WriteString("\n\treturn 0;\n")
+	source.WriteString("}\n")
+
+	return source.String()
+}
+
+// cell returns the C expression for the cell at offset from the current cell
+func cell(offset int) string {
+	if offset == 0 {
+		return "*pointer"
+	}
+
+	return fmt.Sprintf("pointer[%d]", offset)
+}
+
+// stringLiteral returns text as a C string literal, escaping every byte which isn't printable ASCII. Octal escapes
+// always use 3 digits, so a digit after them isn't taken as part of the escape
+func stringLiteral(text string) string {
+	var literal strings.Builder
+	literal.WriteByte('"')
+
+	for i := 0; i < len(text); i++ {
+		character := text[i]
+
+		// question marks are escaped to prevent trigraphs
+		if character < ' ' || character > '~' || character == '"' || character == '\\' || character == '?' {
+			literal.WriteString(fmt.Sprintf("\\%03o", character))
+			continue
+		}
+
+		literal.WriteByte(character)
+	}
+
+	literal.WriteByte('"')
+
+	return literal.String()
+}
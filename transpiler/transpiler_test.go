@@ -0,0 +1,113 @@
+package transpiler
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gobf/execution"
+	"gobf/instructions"
+	"testing"
+)
+
+func TestTranspiler_TranspileToC(t *testing.T) {
+	var testInstructions = []instructions.Instruction{
+		{Name: instructions.MoveRight, Value: 2},
+		{Name: instructions.MoveLeft, Value: 1},
+		{Name: instructions.Increment, Value: 3},
+		{Name: instructions.JumpIfZero, Value: 6},
+		{Name: instructions.Decrement, Value: 1},
+		{Name: instructions.Write, Value: 1},
+		{Name: instructions.JumpUnlessZero, Value: 3},
+		{Name: instructions.Read, Value: 1},
+		{Name: instructions.Clear, Value: 0},
+		{Name: instructions.MulAdd, Value: -2, Offset: 3},
+		{Name: instructions.ScanRight, Value: 2},
+		{Name: instructions.ScanLeft, Value: 1},
+		{Name: instructions.Increment, Value: 2, Offset: -1},
+		{Name: instructions.Write, Value: 1, Offset: 4},
+		{Name: instructions.Set, Value: 72, Offset: 1},
+		{Name: instructions.Set, Value: -1},
+		{Name: instructions.WriteString, Text: "Hi\n\"?"},
+	}
+
+	assert.Equal(t, `#include <stdint.h>
+#include <stdio.h>
+
+static uint8_t memory[1000];
+
+int main(void) {
+	uint8_t *pointer = memory;
+	int character;
+
+	pointer += 2;
+	pointer -= 1;
+	*pointer += 3;
+	while (*pointer) {
+		*pointer -= 1;
+		putchar(*pointer);
+	}
+	fflush(stdout); if ((character = getchar()) != EOF) *pointer = character;
+	*pointer = 0;
+	pointer[3] += *pointer * -2;
+	while (*pointer) pointer += 2;
+	while (*pointer) pointer -= 1;
+	pointer[-1] += 2;
+	putchar(pointer[4]);
+	pointer[1] = 72;
+	*pointer = -1;
+	fwrite("Hi\012\042\077", 1, 5, stdout);
+
+	return 0;
+}
+`, TranspileToC(testInstructions, execution.Options{MemorySize: 1000}))
+}
+
+func TestTranspiler_TranspileToCCellSizes(t *testing.T) {
+	var tests = []struct {
+		cellSize uint
+		expected string
+	}{
+		{8, "static uint8_t memory[10];"},
+		{16, "static uint16_t memory[10];"},
+		{32, "static uint32_t memory[10];"},
+	}
+
+	for _, test := range tests {
+		source := TranspileToC(nil, execution.Options{MemorySize: 10, CellSize: test.cellSize})
+
+		assert.Contains(t, source, test.expected)
+	}
+}
+
+func TestTranspiler_TranspileToCEOF(t *testing.T) {
+	var tests = []struct {
+		options  execution.Options
+		expected string
+	}{
+		{execution.Options{EOF: execution.EOFUnchanged}, "*pointer = character;\n"},
+		{execution.Options{EOF: execution.EOFZero}, "*pointer = character; else *pointer = 0;\n"},
+		{execution.Options{EOF: execution.EOFMinusOne, CellSize: 16}, "*pointer = character; else *pointer = 65535;\n"},
+	}
+
+	for _, test := range tests {
+		source := TranspileToC([]instructions.Instruction{{Name: instructions.Read, Value: 1}}, test.options)
+
+		assert.Contains(t, source, test.expected)
+	}
+}
+
+func TestTranspiler_TranspileToCUnbuffered(t *testing.T) {
+	assert.NotContains(t, TranspileToC(nil, execution.Options{}), "setvbuf")
+	assert.Contains(t, TranspileToC(nil, execution.Options{Unbuffered: true}), "\tsetvbuf(stdout, NULL, _IONBF, 0);\n")
+}
+
+func TestTranspiler_TranspileToCMaxSteps(t *testing.T) {
+	loop := []instructions.Instruction{
+		{Name: instructions.JumpIfZero, Value: 1},
+		{Name: instructions.JumpUnlessZero, Value: 0},
+	}
+
+	assert.NotContains(t, TranspileToC(loop, execution.Options{}), "steps")
+
+	source := TranspileToC(loop, execution.Options{MaxSteps: 10})
+	assert.Contains(t, source, "\tuint64_t steps = 10ULL;\n")
+	assert.Contains(t, source, "\twhile (*pointer) {\n\t\tif (steps-- == 0) return 3;\n\t}\n")
+}